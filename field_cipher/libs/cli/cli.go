@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"field_cipher/libs/securecv"
+	"field_cipher/utils/fileio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Options holds the parsed command line
+type Options struct {
+	Demo    bool
+	Decrypt bool
+	Convert bool
+	In      string
+	Mode    string
+	OutCV   string
+	OutKeys string
+	CV      string
+	Keys    string
+	Field   string
+}
+
+// newFlagSet declares the command line flags, bound to opts
+func newFlagSet(opts *Options) *flag.FlagSet {
+	fs := flag.NewFlagSet("field_cipher", flag.ContinueOnError)
+	fs.BoolVar(&opts.Demo, "demo", false, "run the built-in tests and demos")
+	fs.BoolVar(&opts.Decrypt, "decrypt", false, "decrypt one field instead of encrypting")
+	fs.BoolVar(&opts.Convert, "convert", false, "re-encrypt -cv under -mode, writing -out-cv and -out-keys")
+	fs.StringVar(&opts.In, "in", "", "CV data JSON to encrypt, or - for stdin")
+	fs.StringVar(&opts.Mode, "mode", "multi", "key mode to encrypt or convert to: single or multi")
+	fs.StringVar(&opts.OutCV, "out-cv", "", "where to write the encrypted CV")
+	fs.StringVar(&opts.OutKeys, "out-keys", "", "where to write the keys")
+	fs.StringVar(&opts.CV, "cv", "", "encrypted CV to decrypt from")
+	fs.StringVar(&opts.Keys, "keys", "", "keys file for -cv")
+	fs.StringVar(&opts.Field, "field", "", "field to decrypt")
+	return fs
+}
+
+// Usage writes the usage message to w
+func Usage(w io.Writer) {
+	fs := newFlagSet(&Options{})
+	fs.SetOutput(w)
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "  field_cipher -in cv.json [-mode multi] -out-cv enc.json -out-keys keys.json")
+	fmt.Fprintln(w, "  field_cipher -decrypt -cv enc.json -keys keys.json -field email")
+	fmt.Fprintln(w, "  field_cipher -convert -cv enc.json -keys keys.json -mode single -out-cv new.json -out-keys new_keys.json")
+	fmt.Fprintln(w, "  field_cipher -demo")
+	fmt.Fprintln(w, "Flags:")
+	fs.PrintDefaults()
+}
+
+// Parse parses args and checks that the flags for the chosen action are set
+func Parse(args []string) (*Options, error) {
+	opts := &Options{}
+	fs := newFlagSet(opts)
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	switch {
+	case opts.Demo:
+		return opts, nil
+	case opts.Decrypt && opts.Convert:
+		return nil, fmt.Errorf("-decrypt and -convert cannot be combined")
+	case opts.Decrypt:
+		if opts.CV == "" || opts.Keys == "" || opts.Field == "" {
+			return nil, fmt.Errorf("-decrypt requires -cv, -keys and -field")
+		}
+	case opts.Convert:
+		if opts.CV == "" || opts.Keys == "" || opts.OutCV == "" || opts.OutKeys == "" {
+			return nil, fmt.Errorf("-convert requires -cv, -keys, -out-cv and -out-keys")
+		}
+	default:
+		if opts.In == "" || opts.OutCV == "" || opts.OutKeys == "" {
+			return nil, fmt.Errorf("encrypting requires -in, -out-cv and -out-keys")
+		}
+	}
+	return opts, nil
+}
+
+// Execute encrypts, decrypts or converts as opts describe, writing a
+// decrypted field to stdout (strings as-is, other values as JSON)
+func Execute(opts *Options, stdout io.Writer) error {
+	if opts.Demo {
+		return fmt.Errorf("-demo is handled by the caller")
+	}
+	if opts.Decrypt {
+		return decrypt(opts, stdout)
+	}
+	if opts.Convert {
+		return convert(opts)
+	}
+	return encrypt(opts)
+}
+
+// encrypt loads CV data and writes the encrypted CV and its keys
+func encrypt(opts *Options) error {
+	var cvData map[string]interface{}
+	var err error
+	if opts.In == "-" {
+		cvData, err = fileio.LoadCVDataFromReader(os.Stdin)
+	} else {
+		cvData, err = fileio.LoadCVData(opts.In)
+	}
+	if err != nil {
+		return err
+	}
+
+	cv := securecv.NewSecureCV()
+	if err := cv.LoadCV(cvData, opts.Mode); err != nil {
+		return err
+	}
+	if err := cv.SaveEncryptedCV(opts.OutCV); err != nil {
+		return err
+	}
+	return cv.SaveKeys(opts.OutKeys)
+}
+
+// decrypt restores a CV and writes one field to stdout
+func decrypt(opts *Options, stdout io.Writer) error {
+	cv, err := securecv.LoadPair(opts.CV, opts.Keys)
+	if err != nil {
+		return err
+	}
+
+	value, err := cv.GetField(opts.Field)
+	if err != nil {
+		return err
+	}
+
+	if text, ok := value.(string); ok {
+		_, err = fmt.Fprintln(stdout, text)
+		return err
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, string(encoded))
+	return err
+}
+
+// convert restores a CV, re-encrypts it under opts.Mode and writes the
+// result and its keys to new files
+func convert(opts *Options) error {
+	cv, err := securecv.LoadPair(opts.CV, opts.Keys)
+	if err != nil {
+		return err
+	}
+	if err := cv.ConvertMode(opts.Mode); err != nil {
+		return err
+	}
+	if err := cv.SaveEncryptedCV(opts.OutCV); err != nil {
+		return err
+	}
+	return cv.SaveKeys(opts.OutKeys)
+}
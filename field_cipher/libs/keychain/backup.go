@@ -0,0 +1,276 @@
+package keychain
+
+import (
+	"field_cipher/models"
+	"field_cipher/utils/cryptoutils"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// backupIterations is the PBKDF2 work factor for backup passphrases
+const backupIterations = 600000
+
+// backupKey holds one key node, including its secret bytes
+type backupKey struct {
+	KeyID     string   `json:"key_id"`
+	Key       string   `json:"key"`
+	Timestamp int64    `json:"timestamp"`
+	Revoked   bool     `json:"revoked"`
+	Fields    []string `json:"fields"`
+	Counter   uint64   `json:"nonce_counter,omitempty"`
+	From      string   `json:"rotated_from,omitempty"`
+}
+
+// backupPayload is the plaintext of a backup before encryption
+type backupPayload struct {
+	Keys    []backupKey `json:"keys"`
+	Current string      `json:"current"`
+}
+
+// backupEnvelope is the on-disk form of an encrypted backup
+type backupEnvelope struct {
+	Salt       string                `json:"salt"`
+	Iterations int                   `json:"iterations"`
+	Data       *models.EncryptedData `json:"data"`
+}
+
+// Backup serializes every key, including key bytes, and encrypts the result
+// under a passphrase-derived key. Unlike ExportKeyChain this contains secrets.
+func (kc *KeyChain) Backup(passphrase string) ([]byte, error) {
+	kc.mu.RLock()
+	payload := backupPayload{Keys: make([]backupKey, 0, kc.size)}
+	for node := kc.head; node != nil; node = node.Next {
+		fields := make([]string, 0, len(node.EncryptedFields))
+		for field := range node.EncryptedFields {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		payload.Keys = append(payload.Keys, backupKey{
+			KeyID:     node.KeyID,
+			Key:       base64.StdEncoding.EncodeToString(node.KeyBytes),
+			Timestamp: node.Timestamp,
+			Revoked:   node.Revoked,
+			Fields:    fields,
+			Counter:   node.NonceCounter,
+			From:      node.RotatedFrom,
+		})
+	}
+	if kc.current != nil {
+		payload.Current = kc.current.KeyID
+	}
+	kc.mu.RUnlock()
+
+	plaintext, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize key chain: %v", err)
+	}
+
+	salt, err := cryptoutils.GenerateRandomBytesErr(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate backup salt: %v", err)
+	}
+	key, err := cryptoutils.DeriveKeyFromPassphrase(passphrase, salt, backupIterations)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := cryptoutils.EncryptData(string(plaintext), key, salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt backup: %v", err)
+	}
+
+	return json.Marshal(backupEnvelope{
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		Iterations: backupIterations,
+		Data:       data,
+	})
+}
+
+// RestoreKeyChain decrypts a backup produced by Backup and rebuilds the chain
+// in its original order
+func RestoreKeyChain(data []byte, passphrase string) (*KeyChain, error) {
+	var envelope backupEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid backup: %v", err)
+	}
+	if envelope.Data == nil {
+		return nil, fmt.Errorf("invalid backup: missing data")
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(envelope.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup salt: %v", err)
+	}
+
+	key, err := cryptoutils.DeriveKeyFromPassphrase(passphrase, salt, envelope.Iterations)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := cryptoutils.DecryptData(envelope.Data, key, salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt backup (wrong passphrase?): %v", err)
+	}
+
+	text, ok := plaintext.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid backup payload type")
+	}
+
+	var payload backupPayload
+	if err := json.Unmarshal([]byte(text), &payload); err != nil {
+		return nil, fmt.Errorf("invalid backup payload: %v", err)
+	}
+
+	kc := NewKeyChain()
+	for _, bk := range payload.Keys {
+		keyBytes, err := base64.StdEncoding.DecodeString(bk.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key material for %s: %v", bk.KeyID, err)
+		}
+		if _, exists := kc.keyMap[bk.KeyID]; exists {
+			return nil, fmt.Errorf("duplicate key %s in backup", bk.KeyID)
+		}
+
+		node := &models.KeyNode{
+			KeyID:           bk.KeyID,
+			KeyBytes:        keyBytes,
+			Timestamp:       bk.Timestamp,
+			Revoked:         bk.Revoked,
+			NonceCounter:    bk.Counter,
+			RotatedFrom:     bk.From,
+			EncryptedFields: make(map[string]bool),
+		}
+		for _, field := range bk.Fields {
+			node.EncryptedFields[field] = true
+		}
+		kc.appendNode(node)
+	}
+
+	if current, exists := kc.keyMap[payload.Current]; exists {
+		kc.current = current
+	}
+	return kc, nil
+}
+
+// ExportFull exports every key, revoked ones included, with key bytes and
+// all metadata, in chain order. Unlike the metadata-only ExportKeyChain this
+// contains secrets and is meant for trusted backups; ImportFull reverses it.
+func (kc *KeyChain) ExportFull() (*models.KeyManifest, error) {
+	kc.mu.RLock()
+	defer kc.mu.RUnlock()
+
+	manifest := &models.KeyManifest{
+		Keys:     make(map[string]models.ShareableKey, kc.size),
+		FieldMap: make(map[string]string),
+		Order:    make([]string, 0, kc.size),
+	}
+	for node := kc.head; node != nil; node = node.Next {
+		if err := cryptoutils.ValidateKey(node.KeyBytes); err != nil {
+			return nil, fmt.Errorf("invalid key %s: %v", node.KeyID, err)
+		}
+
+		fields := make([]string, 0, len(node.EncryptedFields))
+		for field := range node.EncryptedFields {
+			fields = append(fields, field)
+			if !node.Revoked {
+				manifest.FieldMap[field] = node.KeyID
+			}
+		}
+		sort.Strings(fields)
+
+		manifest.Keys[node.KeyID] = models.ShareableKey{
+			KeyID:        node.KeyID,
+			Key:          base64.StdEncoding.EncodeToString(node.KeyBytes),
+			KeyHex:       hex.EncodeToString(node.KeyBytes),
+			Fields:       fields,
+			NonceCounter: node.NonceCounter,
+			Fingerprint:  cryptoutils.KeyFingerprint(node.KeyBytes),
+			Timestamp:    node.Timestamp,
+			Revoked:      node.Revoked,
+			ExpiresAt:    node.ExpiresAt,
+			RotatedFrom:  node.RotatedFrom,
+			UsageCount:   node.UsageCount,
+			MaxUsage:     node.MaxUsage,
+		}
+		manifest.Order = append(manifest.Order, node.KeyID)
+	}
+	if kc.current != nil {
+		manifest.Current = kc.current.KeyID
+	}
+	return manifest, nil
+}
+
+// ImportFull rebuilds an empty chain from a manifest produced by ExportFull,
+// restoring key order, the current key, IDs, timestamps and field sets. The
+// chain is left untouched if any key fails to import.
+func (kc *KeyChain) ImportFull(manifest *models.KeyManifest) error {
+	if manifest == nil {
+		return fmt.Errorf("manifest is nil")
+	}
+
+	order := manifest.Order
+	if len(order) != len(manifest.Keys) {
+		return fmt.Errorf("manifest order lists %d keys, manifest has %d", len(order), len(manifest.Keys))
+	}
+
+	staged := NewKeyChain()
+	for _, keyID := range order {
+		shareable, exists := manifest.Keys[keyID]
+		if !exists {
+			return fmt.Errorf("key %s in manifest order is missing from its keys", keyID)
+		}
+		if _, exists := staged.keyMap[keyID]; exists {
+			return fmt.Errorf("duplicate key %s in manifest", keyID)
+		}
+
+		keyBytes, err := shareable.DecodeKey()
+		if err != nil {
+			return fmt.Errorf("invalid key material for %s: %v", keyID, err)
+		}
+		if err := cryptoutils.ValidateKey(keyBytes); err != nil {
+			return fmt.Errorf("invalid key %s: %v", keyID, err)
+		}
+		if shareable.Fingerprint != "" && !cryptoutils.ConstantTimeKeyEqual([]byte(shareable.Fingerprint), []byte(cryptoutils.KeyFingerprint(keyBytes))) {
+			return fmt.Errorf("key %s does not match its fingerprint %s", keyID, shareable.Fingerprint)
+		}
+
+		node := &models.KeyNode{
+			KeyID:           keyID,
+			KeyBytes:        keyBytes,
+			Timestamp:       shareable.Timestamp,
+			Revoked:         shareable.Revoked,
+			NonceCounter:    shareable.NonceCounter,
+			ExpiresAt:       shareable.ExpiresAt,
+			RotatedFrom:     shareable.RotatedFrom,
+			UsageCount:      shareable.UsageCount,
+			MaxUsage:        shareable.MaxUsage,
+			EncryptedFields: make(map[string]bool, len(shareable.Fields)),
+		}
+		for _, field := range shareable.Fields {
+			node.EncryptedFields[field] = true
+		}
+		staged.appendNode(node)
+	}
+	if manifest.Current != "" {
+		current, exists := staged.keyMap[manifest.Current]
+		if !exists {
+			return fmt.Errorf("current key %s is missing from the manifest", manifest.Current)
+		}
+		staged.current = current
+	}
+
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	if kc.size != 0 {
+		return fmt.Errorf("key chain already holds %d keys", kc.size)
+	}
+	kc.head, kc.tail, kc.current = staged.head, staged.tail, staged.current
+	kc.keyMap, kc.size = staged.keyMap, staged.size
+	return nil
+}
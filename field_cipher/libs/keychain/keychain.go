@@ -3,6 +3,10 @@ package keychain
 import (
 	"field_cipher/models"
 	"field_cipher/utils/cryptoutils"  
+	"field_cipher/utils/logging"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"sort"
 	"strings"
@@ -12,28 +16,214 @@ import (
 
 // KeyChain manages encryption keys using a doubly linked list
 type KeyChain struct {
-	mu      sync.RWMutex
-	head    *models.KeyNode
-	tail    *models.KeyNode
-	current *models.KeyNode
-	keyMap  map[string]*models.KeyNode
-	size    int
+	mu         sync.RWMutex
+	head       *models.KeyNode
+	tail       *models.KeyNode
+	current    *models.KeyNode
+	keyMap     map[string]*models.KeyNode
+	size       int
+	seed       []byte
+	usedNonces map[string]map[string]bool // keyID -> nonces; nil when tracking is off
+	derivedIDs bool
+	keyBits    int // size of new keys; 0 means 256
+	logger     logging.Logger
 }
 
 // NewKeyChain creates a new KeyChain
 func NewKeyChain() *KeyChain {
 	return &KeyChain{
 		keyMap: make(map[string]*models.KeyNode),
+		logger: logging.Nop{},
 	}
 }
 
-// CreateKey generates new key and adds to chain
+// SetLogger routes key lifecycle messages (revocation, cleanup, wipe) to
+// logger; nil restores the default, which discards them
+func (kc *KeyChain) SetLogger(logger logging.Logger) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	kc.logger = logging.OrNop(logger)
+}
+
+// NewKeyChainSeeded creates a KeyChain whose per-field keys are derived from
+// a master seed, so two parties with the same seed produce identical keys
+func NewKeyChainSeeded(master []byte) *KeyChain {
+	kc := NewKeyChain()
+	kc.seed = append([]byte{}, master...)
+	return kc
+}
+
+// CreateKeyForField returns the key for field derived from the master seed,
+// creating it on first use. Unseeded chains fall back to a random key.
+func (kc *KeyChain) CreateKeyForField(field string) *models.KeyNode {
+	node, err := kc.CreateKeyForFieldErr(field)
+	if err != nil {
+		panic(err)
+	}
+	return node
+}
+
+// CreateKeyForFieldErr is CreateKeyForField returning an error instead of
+// panicking if an unseeded chain cannot generate a random key or a seeded
+// chain's derived key for field is revoked or expired
+func (kc *KeyChain) CreateKeyForFieldErr(field string) (*models.KeyNode, error) {
+	if kc.seed == nil {
+		return kc.CreateKeyErr()
+	}
+
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	idBytes := cryptoutils.DeriveSubkey(kc.seed, "field_cipher key id "+field)
+	keyID := hex.EncodeToString(idBytes[:8])
+	if node, exists := kc.keyMap[keyID]; exists {
+		// A revoked key's bytes are wiped; an expired one must not be reused
+		if node.Revoked {
+			return nil, fmt.Errorf("derived key %s for field '%s' is revoked", models.ShortID(keyID, 8), field)
+		}
+		if node.PastExpiry() {
+			return nil, fmt.Errorf("derived key %s for field '%s' has expired", models.ShortID(keyID, 8), field)
+		}
+		return node, nil
+	}
+
+	node := &models.KeyNode{
+		KeyID:           keyID,
+		KeyBytes:        cryptoutils.DeriveSubkey(kc.seed, "field_cipher key "+field)[:kc.keyLen()],
+		Timestamp:       time.Now().Unix(),
+		EncryptedFields: make(map[string]bool),
+	}
+
+	kc.appendNode(node)
+	return node, nil
+}
+
+// CreateKey generates new key and adds to chain, panicking if the RNG fails
 func (kc *KeyChain) CreateKey() *models.KeyNode {
+	node, err := kc.CreateKeyErr()
+	if err != nil {
+		panic(err)
+	}
+	return node
+}
+
+// CreateKeyErr generates a new key and adds it to the chain, returning an
+// error instead of panicking if the RNG fails
+func (kc *KeyChain) CreateKeyErr() (*models.KeyNode, error) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	keyBytes, err := cryptoutils.GenerateRandomBytesErr(kc.keyLen())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %v", err)
+	}
+	return kc.createKey(keyBytes)
+}
+
+// CreateKeyWithSize generates a new key of 128, 192 or 256 bits and adds it
+// to the chain
+func (kc *KeyChain) CreateKeyWithSize(bits int) (*models.KeyNode, error) {
+	keyBytes, err := cryptoutils.GenerateAESKey(bits)
+	if err != nil {
+		return nil, err
+	}
+
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	return kc.createKey(keyBytes)
+}
+
+// SetKeySize sets the size in bits (128, 192 or 256) of keys created from now
+// on by CreateKey and CreateKeyForField
+func (kc *KeyChain) SetKeySize(bits int) error {
+	if _, err := cryptoutils.GenerateAESKey(bits); err != nil {
+		return err
+	}
+
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	kc.keyBits = bits
+	return nil
+}
+
+// KeySize returns the size in bits of keys the chain creates
+func (kc *KeyChain) KeySize() int {
+	kc.mu.RLock()
+	defer kc.mu.RUnlock()
+	return kc.keyLen() * 8
+}
+
+// keyLen returns the byte length of new keys; caller holds kc.mu
+func (kc *KeyChain) keyLen() int {
+	if kc.keyBits == 0 {
+		return 32 // AES-256
+	}
+	return kc.keyBits / 8
+}
+
+// createKey adds a new key node for keyBytes; caller holds kc.mu
+func (kc *KeyChain) createKey(keyBytes []byte) (*models.KeyNode, error) {
+	timestamp := time.Now().Unix()
+
+	var keyID string
+	if kc.derivedIDs {
+		keyID = DeriveKeyID(keyBytes, timestamp)
+	} else {
+		var err error
+		if keyID, err = cryptoutils.GenerateRandomHexErr(16); err != nil {
+			return nil, fmt.Errorf("failed to generate key ID: %v", err)
+		}
+	}
+
+	node := &models.KeyNode{
+		KeyID:           keyID,
+		KeyBytes:        keyBytes,
+		Timestamp:       timestamp,
+		EncryptedFields: make(map[string]bool),
+	}
+
+	kc.appendNode(node)
+	return node, nil
+}
+
+// SetDerivedKeyIDs makes CreateKey derive key IDs from the key bytes and
+// creation timestamp instead of drawing them at random
+func (kc *KeyChain) SetDerivedKeyIDs(enabled bool) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	kc.derivedIDs = enabled
+}
+
+// DeriveKeyID returns the key ID CreateKey assigns when derived IDs are on:
+// the first 8 bytes of SHA-256(key bytes || big-endian timestamp) in hex
+func DeriveKeyID(keyBytes []byte, timestamp int64) string {
+	h := sha256.New()
+	h.Write(keyBytes)
+	binary.Write(h, binary.BigEndian, timestamp)
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// CreateKeyWithID generates a new random key under an explicit ID
+func (kc *KeyChain) CreateKeyWithID(keyID string) (*models.KeyNode, error) {
+	if keyID == "" {
+		return nil, fmt.Errorf("key ID is empty")
+	}
+	keyBytes, err := cryptoutils.GenerateRandomBytesErr(kc.KeySize() / 8)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %v", err)
+	}
+	return kc.ImportKey(keyID, keyBytes)
+}
+
+// ImportKey adds existing key material under its original ID
+func (kc *KeyChain) ImportKey(keyID string, keyBytes []byte) (*models.KeyNode, error) {
 	kc.mu.Lock()
 	defer kc.mu.Unlock()
 
-	keyID := cryptoutils.GenerateRandomHex(16)
-	keyBytes := cryptoutils.GenerateRandomBytes(32) // AES-256
+	if _, exists := kc.keyMap[keyID]; exists {
+		return nil, fmt.Errorf("key %s already exists", keyID)
+	}
 
 	node := &models.KeyNode{
 		KeyID:           keyID,
@@ -42,6 +232,12 @@ func (kc *KeyChain) CreateKey() *models.KeyNode {
 		EncryptedFields: make(map[string]bool),
 	}
 
+	kc.appendNode(node)
+	return node, nil
+}
+
+// appendNode links node at the tail and makes it current; caller holds kc.mu
+func (kc *KeyChain) appendNode(node *models.KeyNode) {
 	if kc.head == nil {
 		kc.head = node
 		kc.tail = node
@@ -52,10 +248,8 @@ func (kc *KeyChain) CreateKey() *models.KeyNode {
 	}
 
 	kc.current = node
-	kc.keyMap[keyID] = node
+	kc.keyMap[node.KeyID] = node
 	kc.size++
-
-	return node
 }
 
 // GetKeyBytes retrieves key bytes by ID
@@ -70,6 +264,9 @@ func (kc *KeyChain) GetKeyBytes(keyID string) ([]byte, error) {
 	if node.Revoked {
 		return nil, fmt.Errorf("key revoked")
 	}
+	if node.PastExpiry() {
+		return nil, fmt.Errorf("key expired")
+	}
 	return node.KeyBytes, nil
 }
 
@@ -80,7 +277,7 @@ func (kc *KeyChain) GetNode(keyID string) *models.KeyNode {
 	return kc.keyMap[keyID]
 }
 
-// RevokeKey marks key as revoked
+// RevokeKey marks key as revoked and zeroizes its key bytes
 func (kc *KeyChain) RevokeKey(keyID string) error {
 	kc.mu.Lock()
 	defer kc.mu.Unlock()
@@ -92,6 +289,44 @@ func (kc *KeyChain) RevokeKey(keyID string) error {
 
 	node.Revoked = true
 	node.Timestamp = time.Now().Unix()
+	cryptoutils.Zeroize(node.KeyBytes)
+	kc.logger.Printf("Revoked key %s...", models.ShortID(keyID, 8))
+	return nil
+}
+
+// SetKeyTTL makes a key unusable once ttl has elapsed from now
+func (kc *KeyChain) SetKeyTTL(keyID string, ttl time.Duration) error {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	node, exists := kc.keyMap[keyID]
+	if !exists {
+		return fmt.Errorf("key not found")
+	}
+	if ttl <= 0 {
+		return fmt.Errorf("invalid key TTL: %v", ttl)
+	}
+
+	node.ExpiresAt = time.Now().Add(ttl).UnixNano()
+	return nil
+}
+
+// SetMaxUsage caps the number of encryptions under keyID; once reached, the
+// next SecureCV encryption that would use the key rotates to a new one. 0
+// removes the cap.
+func (kc *KeyChain) SetMaxUsage(keyID string, max int) error {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	node, exists := kc.keyMap[keyID]
+	if !exists {
+		return fmt.Errorf("key not found")
+	}
+	if max < 0 {
+		return fmt.Errorf("invalid max usage: %d", max)
+	}
+
+	node.MaxUsage = uint64(max)
 	return nil
 }
 
@@ -135,6 +370,38 @@ func (kc *KeyChain) GetAllKeys() []*models.KeyNode {
 	return keys
 }
 
+// GetOrphanedKeys returns the non-revoked keys that encrypt no fields, in
+// chain order
+func (kc *KeyChain) GetOrphanedKeys() []*models.KeyNode {
+	kc.mu.RLock()
+	defer kc.mu.RUnlock()
+
+	keys := make([]*models.KeyNode, 0)
+	for node := kc.head; node != nil; node = node.Next {
+		if !node.Revoked && len(node.EncryptedFields) == 0 {
+			keys = append(keys, node)
+		}
+	}
+	return keys
+}
+
+// Iterate calls fn for every key, revoked ones included, from head to tail
+// (creation order) with its 0-based position, stopping early when fn returns
+// false. The chain is read-locked throughout, so fn must not call back into
+// the KeyChain.
+func (kc *KeyChain) Iterate(fn func(pos int, node *models.KeyNode) bool) {
+	kc.mu.RLock()
+	defer kc.mu.RUnlock()
+
+	pos := 0
+	for node := kc.head; node != nil; node = node.Next {
+		if !fn(pos, node) {
+			return
+		}
+		pos++
+	}
+}
+
 // GetRevokedKeys returns all revoked keys
 func (kc *KeyChain) GetRevokedKeys() []*models.KeyNode {
 	kc.mu.RLock()
@@ -200,8 +467,10 @@ func (kc *KeyChain) GetKeyStats() map[string]interface{} {
 	stats["active_keys"] = active
 	stats["revoked_keys"] = revoked
 	stats["current_key_id"] = ""
+	stats["key_size_bits"] = 0
 	if kc.current != nil {
 		stats["current_key_id"] = kc.current.KeyID
+		stats["key_size_bits"] = len(kc.current.KeyBytes) * 8
 	}
 	
 	return stats
@@ -221,36 +490,106 @@ func (kc *KeyChain) CleanupRevokedKeys(maxAge time.Duration) int {
 		next := node.Next
 		
 		if node.Revoked && node.Timestamp < cutoff {
-			// Remove node from linked list
-			if node.Prev != nil {
-				node.Prev.Next = node.Next
-			} else {
-				kc.head = node.Next
-			}
-			
-			if node.Next != nil {
-				node.Next.Prev = node.Prev
-			} else {
-				kc.tail = node.Prev
-			}
-			
-			// Remove from map
-			delete(kc.keyMap, node.KeyID)
-			kc.size--
+			kc.unlink(node)
 			removed++
-			
-			// Update current if it was removed
-			if kc.current == node {
-				kc.current = kc.tail
-			}
 		}
 		
 		node = next
 	}
 
+	if removed > 0 {
+		kc.logger.Printf("Removed %d revoked keys", removed)
+	}
 	return removed
 }
 
+// RemoveKey deletes a key from the chain and zeroizes its bytes, e.g. to
+// undo keys created by a failed load. Fields still mapped to it become
+// undecryptable.
+func (kc *KeyChain) RemoveKey(keyID string) error {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	node, exists := kc.keyMap[keyID]
+	if !exists {
+		return fmt.Errorf("key not found")
+	}
+	kc.unlink(node)
+	return nil
+}
+
+// unlink removes node from the linked list and map, wiping its key material;
+// caller holds kc.mu
+func (kc *KeyChain) unlink(node *models.KeyNode) {
+	if node.Prev != nil {
+		node.Prev.Next = node.Next
+	} else {
+		kc.head = node.Next
+	}
+
+	if node.Next != nil {
+		node.Next.Prev = node.Prev
+	} else {
+		kc.tail = node.Prev
+	}
+
+	cryptoutils.Zeroize(node.KeyBytes)
+	delete(kc.keyMap, node.KeyID)
+	delete(kc.usedNonces, node.KeyID)
+	kc.size--
+
+	// Update current if it was removed
+	if kc.current == node {
+		kc.current = kc.tail
+	}
+}
+
+// SetNonceTracking turns nonce reuse detection on or off. Tracking remembers
+// every nonce used per key, so memory grows with the number of encryptions;
+// turning it off discards the history.
+func (kc *KeyChain) SetNonceTracking(enabled bool) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	if !enabled {
+		kc.usedNonces = nil
+	} else if kc.usedNonces == nil {
+		kc.usedNonces = make(map[string]map[string]bool)
+	}
+}
+
+// RecordNonce notes that nonce was used with keyID and fails if it was used
+// before, which would mean a broken RNG; a no-op when tracking is off
+func (kc *KeyChain) RecordNonce(keyID string, nonce []byte) error {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	if kc.usedNonces == nil {
+		return nil
+	}
+	if kc.usedNonces[keyID] == nil {
+		kc.usedNonces[keyID] = make(map[string]bool)
+	}
+	if kc.usedNonces[keyID][string(nonce)] {
+		return fmt.Errorf("nonce reuse detected for key %s", keyID)
+	}
+	kc.usedNonces[keyID][string(nonce)] = true
+	return nil
+}
+
+// SecureWipe zeroizes every key's bytes, e.g. at shutdown; the chain is
+// unusable afterwards
+func (kc *KeyChain) SecureWipe() {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	for node := kc.head; node != nil; node = node.Next {
+		cryptoutils.Zeroize(node.KeyBytes)
+	}
+	cryptoutils.Zeroize(kc.seed)
+	kc.logger.Printf("Wiped %d keys", kc.size)
+}
+
 // ExportKeyChain exports the key chain for backup
 func (kc *KeyChain) ExportKeyChain() *models.KeyManifest {
 	kc.mu.RLock()
@@ -274,8 +613,9 @@ func (kc *KeyChain) ExportKeyChain() *models.KeyManifest {
 			sort.Strings(fields)
 
 			manifest.Keys[node.KeyID] = models.ShareableKey{
-				KeyID: node.KeyID,
-				Fields: fields,
+				KeyID:     node.KeyID,
+				Fields:    fields,
+				Timestamp: node.Timestamp,
 			}
 		}
 		node = node.Next
@@ -0,0 +1,38 @@
+package securecv
+
+import "time"
+
+// AccessStat reports how often a field was decrypted through GetField
+type AccessStat struct {
+	Count      int
+	LastAccess time.Time // zero if never accessed
+}
+
+// recordAccess counts a successful GetField. Readers hold scv.mu only for
+// reading, so the counters have their own lock.
+func (scv *SecureCV) recordAccess(field string) {
+	scv.statsMu.Lock()
+	defer scv.statsMu.Unlock()
+
+	scv.accessCount[field]++
+	scv.lastAccess[field] = scv.now().UnixNano()
+}
+
+// GetAccessStats returns access counts and last-access times for every field,
+// including fields that were never read
+func (scv *SecureCV) GetAccessStats() map[string]AccessStat {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+	scv.statsMu.Lock()
+	defer scv.statsMu.Unlock()
+
+	stats := make(map[string]AccessStat, len(scv.encrypted))
+	for field := range scv.encrypted {
+		stat := AccessStat{Count: scv.accessCount[field]}
+		if last, accessed := scv.lastAccess[field]; accessed {
+			stat.LastAccess = time.Unix(0, last)
+		}
+		stats[field] = stat
+	}
+	return stats
+}
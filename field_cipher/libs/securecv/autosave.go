@@ -0,0 +1,46 @@
+package securecv
+
+// SetAutosave sets the files that Flush persists the CV and its keys to.
+// Mutations only mark the CV dirty; nothing is written until Flush.
+func (scv *SecureCV) SetAutosave(cvFile, keysFile string) {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	scv.autosaveCV = cvFile
+	scv.autosaveKeys = keysFile
+	scv.dirty.Store(true)
+}
+
+// HasPendingChanges reports whether there are mutations not yet flushed
+func (scv *SecureCV) HasPendingChanges() bool {
+	return scv.dirty.Load()
+}
+
+// Flush writes pending changes to the autosave files. It is idempotent (a
+// second call with nothing pending is a no-op), serialized against other
+// flushes, and only takes the read lock, so readers are never blocked.
+func (scv *SecureCV) Flush() error {
+	scv.flushMu.Lock()
+	defer scv.flushMu.Unlock()
+
+	if !scv.dirty.Load() {
+		return nil
+	}
+
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	if scv.autosaveCV == "" || scv.autosaveKeys == "" {
+		return nil
+	}
+
+	// Mutations need the write lock, so both files come from one snapshot
+	if err := scv.saveEncryptedCV(scv.autosaveCV); err != nil {
+		return err
+	}
+	if err := scv.saveJSON(scv.autosaveKeys, scv.keyManifest()); err != nil {
+		return err
+	}
+	scv.dirty.Store(false)
+	return nil
+}
@@ -0,0 +1,58 @@
+package securecv
+
+import (
+	"field_cipher/utils/fileio"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// EncryptDirectory encrypts every *.json CV in inDir with its own SecureCV,
+// writing <name>.enc.json and <name>.keys.json to outDir. A failing file does
+// not stop the batch: the processed files are returned along with an error
+// naming each file that failed.
+func EncryptDirectory(inDir, outDir, mode string) ([]string, error) {
+	files, err := fileio.ListFiles(inDir, "json")
+	if err != nil {
+		return nil, err
+	}
+	if err := fileio.EnsureDirectory(outDir); err != nil {
+		return nil, err
+	}
+
+	var processed []string
+	var errs []error
+	for _, file := range files {
+		// Skip outputs of an earlier run when outDir is inDir
+		if strings.HasSuffix(file, ".enc.json") || strings.HasSuffix(file, ".keys.json") {
+			continue
+		}
+		if err := encryptFile(filepath.Join(inDir, file), outDir, mode); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", file, err))
+			continue
+		}
+		processed = append(processed, file)
+	}
+
+	return processed, errors.Join(errs...)
+}
+
+// encryptFile encrypts one CV file into outDir
+func encryptFile(filename, outDir, mode string) error {
+	cvData, err := fileio.LoadCVData(filename)
+	if err != nil {
+		return err
+	}
+
+	scv := NewSecureCV()
+	if err := scv.LoadCV(cvData, mode); err != nil {
+		return err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(filename), ".json")
+	if err := scv.SaveEncryptedCV(filepath.Join(outDir, name+".enc.json")); err != nil {
+		return err
+	}
+	return scv.SaveKeys(filepath.Join(outDir, name+".keys.json"))
+}
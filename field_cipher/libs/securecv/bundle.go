@@ -0,0 +1,63 @@
+package securecv
+
+import (
+	"field_cipher/models"
+	"field_cipher/utils/fileio"
+	"fmt"
+)
+
+// bundleVersion is the CVBundle format written by ExportBundle
+const bundleVersion = 1
+
+// ExportBundle writes the encrypted CV, its field map and metadata to one
+// JSON file. With includeKeys the key manifest is embedded too, so anyone
+// holding the file can decrypt it; send such bundles only over a secure
+// channel.
+func (scv *SecureCV) ExportBundle(filename string, includeKeys bool) error {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	bundle := &models.CVBundle{
+		Version:   bundleVersion,
+		CreatedAt: scv.now().Unix(),
+		CV:        scv.encryptedCV(),
+	}
+	if includeKeys {
+		bundle.Keys = scv.keyManifest()
+	}
+	return scv.saveJSON(filename, bundle)
+}
+
+// ImportBundle loads a file written by ExportBundle. When the bundle carries
+// keys they are imported and checked against the data, so the result is
+// ready for GetField; otherwise load keys separately with LoadKeys.
+func ImportBundle(filename string) (*SecureCV, error) {
+	var bundle models.CVBundle
+	if err := fileio.LoadJSON(filename, &bundle); err != nil {
+		return nil, err
+	}
+	if bundle.Version != bundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", bundle.Version)
+	}
+	if bundle.CV == nil {
+		return nil, fmt.Errorf("bundle has no encrypted CV")
+	}
+
+	scv := NewSecureCV()
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	if err := scv.applyEncryptedCV(bundle.CV); err != nil {
+		return nil, err
+	}
+	if bundle.Keys != nil {
+		if err := scv.importManifest(bundle.Keys); err != nil {
+			return nil, err
+		}
+		if err := scv.reconcile(); err != nil {
+			return nil, err
+		}
+	}
+	return scv, nil
+}
+
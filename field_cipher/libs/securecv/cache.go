@@ -0,0 +1,164 @@
+package securecv
+
+import (
+	"field_cipher/models"
+	"container/list"
+	"sync"
+)
+
+// decryptCache is an LRU cache of decrypted field values. An entry only hits
+// while the field still has the same stored record under the same key, so a
+// replaced ciphertext can never return a stale value. A nil cache is disabled.
+type decryptCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // most recently used at the front
+	entries  map[string]*list.Element
+	hits     int
+	misses   int
+}
+
+// cacheEntry is one cached plaintext
+type cacheEntry struct {
+	field  string
+	keyID  string
+	record *models.EncryptedData
+	value  interface{}
+}
+
+// EnableDecryptCache caches up to capacity decrypted fields so repeated reads
+// skip AES-GCM; capacity 0 or less disables the cache. Entries are dropped
+// when their field is rotated, overwritten or removed, or its key revoked.
+// GetStats reports cache_hits and cache_misses.
+func (scv *SecureCV) EnableDecryptCache(capacity int) {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	if capacity <= 0 {
+		scv.cache = nil
+		return
+	}
+	scv.cache = &decryptCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// get returns a copy of the cached value for field if it was decrypted from
+// record under keyID
+func (c *decryptCache) get(field, keyID string, record *models.EncryptedData) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, exists := c.entries[field]
+	if !exists {
+		c.misses++
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if entry.keyID != keyID || entry.record != record {
+		c.misses++
+		return nil, false
+	}
+
+	c.hits++
+	c.order.MoveToFront(elem)
+	return copyValue(entry.value), true
+}
+
+// put caches a copy of value, evicting the least recently used entry when full
+func (c *decryptCache) put(field, keyID string, record *models.EncryptedData, value interface{}) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &cacheEntry{field: field, keyID: keyID, record: record, value: copyValue(value)}
+	if elem, exists := c.entries[field]; exists {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[field] = c.order.PushFront(entry)
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).field)
+	}
+}
+
+// invalidate drops the entry for field
+func (c *decryptCache) invalidate(field string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, exists := c.entries[field]; exists {
+		c.order.Remove(elem)
+		delete(c.entries, field)
+	}
+}
+
+// invalidateKey drops every entry decrypted under keyID
+func (c *decryptCache) invalidateKey(keyID string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for field, elem := range c.entries {
+		if elem.Value.(*cacheEntry).keyID == keyID {
+			c.order.Remove(elem)
+			delete(c.entries, field)
+		}
+	}
+}
+
+// clear drops every entry, keeping the counters
+func (c *decryptCache) clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.entries = make(map[string]*list.Element)
+}
+
+// stats returns the hit and miss counts and the number of cached entries
+func (c *decryptCache) stats() (int, int, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses, c.order.Len()
+}
+
+// copyValue deep-copies the maps and slices a decrypted value may hold, so
+// callers cannot modify cached values
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			copied[k] = copyValue(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyValue(item)
+		}
+		return copied
+	default:
+		return value
+	}
+}
@@ -0,0 +1,96 @@
+package securecv
+
+import (
+	"field_cipher/models"
+	"context"
+	"fmt"
+	"sort"
+)
+
+// LoadCVContext is LoadCV that checks ctx between fields, in name order. If
+// ctx is cancelled or times out, or a field fails to encrypt, every field it
+// loaded is put back as it was, the keys it created are removed and the
+// error (ctx.Err() for cancellation) is returned.
+func (scv *SecureCV) LoadCVContext(ctx context.Context, cvData map[string]interface{}, mode string) error {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	if cvData == nil {
+		return fmt.Errorf("cv data is nil")
+	}
+	if mode != "single" && mode != "multi" {
+		return fmt.Errorf("unknown mode %q", mode)
+	}
+	if err := scv.checkFieldSizes(cvData); err != nil {
+		return err
+	}
+
+	fields := make([]string, 0, len(cvData))
+	for field := range cvData {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	existingKeys := make(map[string]bool, scv.keys.Size())
+	for _, node := range scv.keys.GetAllKeys() {
+		existingKeys[node.KeyID] = true
+	}
+	current := scv.keys.GetCurrentKey()
+
+	scv.logf("Loading %d CV fields in '%s' mode...", len(cvData), mode)
+
+	before := make([]*FieldState, 0, len(fields))
+	for i, field := range fields {
+		err := ctx.Err()
+		if err == nil {
+			before = append(before, scv.fieldState(field))
+			var keyNode *models.KeyNode
+			if keyNode, err = scv.loadKeyFor(field, mode); err == nil {
+				err = scv.loadField(field, cvData[field], keyNode)
+			}
+		}
+		if err != nil {
+			if rollbackErr := scv.rollbackLoad(fields[:len(before)], before, existingKeys, current); rollbackErr != nil {
+				return fmt.Errorf("%v (rollback failed: %v)", err, rollbackErr)
+			}
+			scv.logf("Load stopped after %d of %d fields and was rolled back: %v", i, len(fields), err)
+			return err
+		}
+	}
+
+	scv.logf("Encrypted %d fields with %d keys", len(cvData), scv.keys.Size())
+	return nil
+}
+
+// fieldState captures field's current ciphertext and key ID, or nil if the
+// field does not exist; caller holds scv.mu
+func (scv *SecureCV) fieldState(field string) *FieldState {
+	encryptedData, exists := scv.encrypted[field]
+	if !exists {
+		return nil
+	}
+	return &FieldState{KeyID: scv.fieldKeyMap[field], Encrypted: encryptedData}
+}
+
+// rollbackLoad restores fields to their before-images, newest first, then
+// removes keys that did not exist before the load and restores the current
+// key; caller holds scv.mu
+func (scv *SecureCV) rollbackLoad(fields []string, before []*FieldState, existingKeys map[string]bool, current *models.KeyNode) error {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if err := scv.restoreFieldState(fields[i], before[i]); err != nil {
+			return err
+		}
+	}
+
+	for _, node := range scv.keys.GetAllKeys() {
+		if !existingKeys[node.KeyID] {
+			if err := scv.keys.RemoveKey(node.KeyID); err != nil {
+				return err
+			}
+		}
+	}
+	if current != nil && !current.Revoked {
+		return scv.keys.SetCurrentKey(current.KeyID)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package securecv
+
+import (
+	"field_cipher/models"
+	"fmt"
+	"sort"
+)
+
+// ConvertMode re-encrypts every field under the key topology of newMode:
+// "single" puts all fields under one new key, "multi" gives each field a new
+// key of its own. The keys the fields were under are revoked afterwards.
+// Every field is re-encrypted before anything is stored, so a failure leaves
+// the CV as it was.
+func (scv *SecureCV) ConvertMode(newMode string) error {
+	if newMode != "single" && newMode != "multi" {
+		return fmt.Errorf("unknown mode %q", newMode)
+	}
+
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	fields := make([]string, 0, len(scv.encrypted))
+	for field := range scv.encrypted {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	// Stage the new keys outside the chain until every field succeeds
+	staged := make(map[string]*models.EncryptedData, len(fields))
+	stagedNodes := make(map[string]*models.KeyNode, len(fields))
+	var shared *models.KeyNode
+	for _, field := range fields {
+		plaintext, err := scv.decryptField(field)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt '%s' for conversion: %v", field, err)
+		}
+
+		node := shared
+		if node == nil {
+			if node, err = scv.stagedKey(); err != nil {
+				return err
+			}
+			if newMode == "single" {
+				shared = node
+			}
+		}
+		stagedNodes[field] = node
+
+		if staged[field], err = scv.encryptField(field, plaintext, node); err != nil {
+			return fmt.Errorf("failed to re-encrypt '%s': %v", field, err)
+		}
+	}
+
+	previous := scv.keys.GetCurrentKey()
+	nodes := make(map[*models.KeyNode]*models.KeyNode)
+	imported := make([]*models.KeyNode, 0, len(fields))
+	for _, field := range fields {
+		stagedNode := stagedNodes[field]
+		if _, imported := nodes[stagedNode]; imported {
+			continue
+		}
+		node, err := scv.keys.ImportKey(stagedNode.KeyID, stagedNode.KeyBytes)
+		if err != nil {
+			scv.discardImported(imported, previous)
+			return err
+		}
+		imported = append(imported, node)
+		node.NonceCounter = stagedNode.NonceCounter
+		node.UsageCount = stagedNode.UsageCount
+		nodes[stagedNode] = node
+	}
+
+	oldKeyIDs := make(map[string]bool)
+	before := make(map[string]*FieldState, len(fields))
+	for _, field := range fields {
+		oldKeyIDs[scv.fieldKeyMap[field]] = true
+		before[field] = &FieldState{KeyID: scv.fieldKeyMap[field], Encrypted: scv.encrypted[field]}
+
+		if err := scv.storeField("convert", field, staged[field], nodes[stagedNodes[field]]); err != nil {
+			for restored, state := range before {
+				scv.restoreFieldState(restored, state)
+			}
+			scv.discardImported(imported, previous)
+			return fmt.Errorf("conversion rolled back: %v", err)
+		}
+	}
+
+	for keyID := range oldKeyIDs {
+		if node := scv.keys.GetNode(keyID); node != nil && !node.Revoked && len(node.EncryptedFields) == 0 {
+			if err := scv.revokeKey(keyID); err != nil {
+				return err
+			}
+		}
+	}
+
+	scv.logf("Converted %d fields to '%s' mode on %d new keys", len(fields), newMode, len(nodes))
+	return nil
+}
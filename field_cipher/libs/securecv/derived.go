@@ -0,0 +1,86 @@
+package securecv
+
+import (
+	"field_cipher/models"
+	"field_cipher/utils/cryptoutils"
+	"encoding/hex"
+	"fmt"
+)
+
+// LoadCVDerived encrypts each field under its own key derived from rootKey
+// via HKDF with the field name as info. Only the root key is stored in the
+// key chain, so it is the only key that needs backing up; decryption
+// re-derives each field's key. Rotating a field moves it to a new random key.
+func (scv *SecureCV) LoadCVDerived(cvData map[string]interface{}, rootKey []byte) error {
+	if cvData == nil {
+		return fmt.Errorf("cv data is nil")
+	}
+	if err := cryptoutils.ValidateKey(rootKey); err != nil {
+		return fmt.Errorf("invalid root key: %v", err)
+	}
+
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	if err := scv.checkFieldSizes(cvData); err != nil {
+		return err
+	}
+	root, err := scv.importRootKey(rootKey)
+	if err != nil {
+		return err
+	}
+
+	scv.logf("Loading %d CV fields with keys derived from root %s...", len(cvData), models.ShortID(root.KeyID, 8))
+
+	for field, value := range cvData {
+		// Encrypt under a stand-in node carrying the subkey; nonces are still
+		// recorded and counted against the root
+		subkey := &models.KeyNode{
+			KeyID:        root.KeyID,
+			KeyBytes:     derivedFieldKey(root.KeyBytes, field),
+			NonceCounter: root.NonceCounter,
+			UsageCount:   root.UsageCount,
+		}
+		encryptedData, err := scv.encryptField(field, value, subkey)
+		root.NonceCounter = subkey.NonceCounter
+		root.UsageCount = subkey.UsageCount
+		if err != nil {
+			return fmt.Errorf("failed to encrypt field %s: %v", field, err)
+		}
+		encryptedData.Derived = true
+		if err := scv.storeField("load", field, encryptedData, root); err != nil {
+			return err
+		}
+	}
+
+	scv.logf("Encrypted %d fields with 1 root key", len(cvData))
+	return nil
+}
+
+// importRootKey adds rootKey to the chain under an ID derived from it, or
+// returns the existing node when the same root was loaded before; caller
+// holds scv.mu
+func (scv *SecureCV) importRootKey(rootKey []byte) (*models.KeyNode, error) {
+	keyID := hex.EncodeToString(cryptoutils.DeriveSubkey(rootKey, "field_cipher root id")[:8])
+	if node := scv.keys.GetNode(keyID); node != nil {
+		if node.Revoked || !cryptoutils.ConstantTimeKeyEqual(node.KeyBytes, rootKey) {
+			return nil, fmt.Errorf("root key %s is revoked or conflicts with an existing key", keyID)
+		}
+		return node, nil
+	}
+	return scv.keys.ImportKey(keyID, append([]byte{}, rootKey...))
+}
+
+// derivedFieldKey derives field's key from root, matching root's key size
+func derivedFieldKey(root []byte, field string) []byte {
+	return cryptoutils.DeriveSubkey(root, "field_cipher field "+field)[:len(root)]
+}
+
+// fieldKeyBytes returns the key that actually encrypts field: keyBytes
+// itself, or the subkey derived from it for LoadCVDerived fields
+func fieldKeyBytes(field string, encryptedData *models.EncryptedData, keyBytes []byte) []byte {
+	if encryptedData.Derived {
+		return derivedFieldKey(keyBytes, field)
+	}
+	return keyBytes
+}
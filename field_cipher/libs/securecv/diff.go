@@ -0,0 +1,45 @@
+package securecv
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// DiffFields compares two CVs by decrypted value: added fields are only in
+// b, removed fields only in a, and changed fields are in both with different
+// plaintext. Every field of both CVs must decrypt. Each list is sorted.
+func DiffFields(a, b *SecureCV) (added, removed, changed []string, err error) {
+	if a == nil || b == nil {
+		return nil, nil, nil, fmt.Errorf("cannot diff a nil SecureCV")
+	}
+
+	// Decrypt each side under its own lock so the two are never held together
+	before, err := a.decryptAll()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("first CV: %v", err)
+	}
+	after, err := b.decryptAll()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("second CV: %v", err)
+	}
+
+	for field, value := range after {
+		old, exists := before[field]
+		if !exists {
+			added = append(added, field)
+		} else if !reflect.DeepEqual(old, value) {
+			changed = append(changed, field)
+		}
+	}
+	for field := range before {
+		if _, exists := after[field]; !exists {
+			removed = append(removed, field)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(changed)
+	return added, removed, changed, nil
+}
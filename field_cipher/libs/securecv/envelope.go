@@ -0,0 +1,118 @@
+package securecv
+
+import (
+	"field_cipher/models"
+	"field_cipher/utils/cryptoutils"
+	"encoding/base64"
+	"fmt"
+)
+
+// SaveKeysWrapped saves the key manifest with every data key wrapped under
+// kek, so only the master key needs protecting
+func (scv *SecureCV) SaveKeysWrapped(filename string, kek []byte) error {
+	manifest := scv.GetAllKeys()
+	if err := wrapManifest(manifest, kek); err != nil {
+		return err
+	}
+	if err := scv.saveJSON(filename, manifest); err != nil {
+		return err
+	}
+
+	scv.mu.Lock()
+	scv.wrappedKeys = filename
+	scv.mu.Unlock()
+	return nil
+}
+
+// LoadKeysWrapped reads a manifest saved by SaveKeysWrapped, unwraps each
+// data key with kek and imports them into the key chain
+func (scv *SecureCV) LoadKeysWrapped(filename string, kek []byte) error {
+	var manifest models.KeyManifest
+	if err := scv.loadJSON(filename, &manifest); err != nil {
+		return err
+	}
+	if err := unwrapManifest(&manifest, kek); err != nil {
+		return err
+	}
+
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	if err := scv.checkManifestFields(&manifest); err != nil {
+		return err
+	}
+	if err := scv.importManifest(&manifest); err != nil {
+		return err
+	}
+	scv.wrappedKeys = filename
+	return nil
+}
+
+// ReKeyMaster rewraps every data key in the wrapped key file under newKEK.
+// Field ciphertexts and data keys are untouched, so this is far cheaper than
+// RotateAllKeys. The file is left as it was if any key fails to unwrap with
+// oldKEK.
+func (scv *SecureCV) ReKeyMaster(oldKEK, newKEK []byte) error {
+	if err := cryptoutils.ValidateKey(newKEK); err != nil {
+		return fmt.Errorf("invalid new master key: %v", err)
+	}
+
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	if scv.wrappedKeys == "" {
+		return fmt.Errorf("no wrapped key file: call SaveKeysWrapped or LoadKeysWrapped first")
+	}
+
+	var manifest models.KeyManifest
+	if err := scv.loadJSON(scv.wrappedKeys, &manifest); err != nil {
+		return err
+	}
+	if err := unwrapManifest(&manifest, oldKEK); err != nil {
+		return err
+	}
+	if err := wrapManifest(&manifest, newKEK); err != nil {
+		return err
+	}
+	return scv.saveJSON(scv.wrappedKeys, &manifest)
+}
+
+// wrapManifest replaces each raw key in the manifest with its wrapped form
+func wrapManifest(manifest *models.KeyManifest, kek []byte) error {
+	for keyID, shareable := range manifest.Keys {
+		dek, err := shareable.DecodeKey()
+		if err != nil {
+			return fmt.Errorf("invalid key material for %s: %v", keyID, err)
+		}
+		wrapped, err := cryptoutils.WrapKey(dek, kek)
+		cryptoutils.Zeroize(dek)
+		if err != nil {
+			return fmt.Errorf("failed to wrap key %s: %v", keyID, err)
+		}
+		shareable.Key = ""
+		shareable.KeyHex = ""
+		shareable.Wrapped = wrapped
+		manifest.Keys[keyID] = shareable
+	}
+	return nil
+}
+
+// unwrapManifest replaces each wrapped key in the manifest with its raw
+// form; nothing is modified unless every key unwraps
+func unwrapManifest(manifest *models.KeyManifest, kek []byte) error {
+	unwrapped := make(map[string]models.ShareableKey, len(manifest.Keys))
+	for keyID, shareable := range manifest.Keys {
+		if shareable.Wrapped == nil {
+			return fmt.Errorf("key %s is not wrapped", keyID)
+		}
+		dek, err := cryptoutils.UnwrapKey(shareable.Wrapped, kek)
+		if err != nil {
+			return fmt.Errorf("failed to unwrap key %s: %v", keyID, err)
+		}
+		shareable.Key = base64.StdEncoding.EncodeToString(dek)
+		shareable.Wrapped = nil
+		unwrapped[keyID] = shareable
+	}
+	manifest.Keys = unwrapped
+	return nil
+}
@@ -0,0 +1,184 @@
+package securecv
+
+import (
+	"field_cipher/models"
+	"field_cipher/utils/cryptoutils"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RotationEstimate describes the expected cost of rotating a set of fields
+type RotationEstimate struct {
+	Fields         int
+	PlaintextBytes int
+	KeysMinted     int
+	EstimatedTime  time.Duration
+	Missing        []string
+}
+
+// FieldSize pairs a field with its raw ciphertext length in bytes
+type FieldSize struct {
+	Field string
+	Size  int
+}
+
+// ciphertextLen returns the decoded ciphertext length, or 0 if malformed
+func ciphertextLen(encryptedData *models.EncryptedData) int {
+	ciphertext, err := base64.StdEncoding.DecodeString(encryptedData.Ciphertext)
+	if err != nil {
+		return 0
+	}
+	return len(ciphertext)
+}
+
+// FieldsBySize lists fields by ciphertext length, largest first, to help spot
+// anomalously large values. Nothing is decrypted.
+func (scv *SecureCV) FieldsBySize() []FieldSize {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	sizes := make([]FieldSize, 0, len(scv.encrypted))
+	for field, encryptedData := range scv.encrypted {
+		sizes = append(sizes, FieldSize{Field: field, Size: ciphertextLen(encryptedData)})
+	}
+
+	sort.Slice(sizes, func(i, j int) bool {
+		if sizes[i].Size != sizes[j].Size {
+			return sizes[i].Size > sizes[j].Size
+		}
+		return sizes[i].Field < sizes[j].Field
+	})
+	return sizes
+}
+
+// ExportRedacted describes the CV's shape without content: each field maps
+// to its value type and ciphertext length. No ciphertext, plaintext or key
+// material is included.
+func (scv *SecureCV) ExportRedacted() map[string]interface{} {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	redacted := make(map[string]interface{}, len(scv.encrypted))
+	for field, encryptedData := range scv.encrypted {
+		valueType := encryptedData.Type
+		if valueType == "" {
+			valueType = "string"
+		}
+		redacted[field] = map[string]interface{}{
+			"type":              valueType,
+			"ciphertext_length": ciphertextLen(encryptedData),
+		}
+	}
+	return redacted
+}
+
+// fallbackThroughput is a conservative estimate used when calibration fails
+const fallbackThroughput = 100 * 1024 * 1024
+
+var (
+	throughputOnce sync.Once
+	throughput     float64 // bytes per second for a decrypt+encrypt round trip
+)
+
+// calibratedThroughput measures AES-GCM round-trip speed once per process
+func calibratedThroughput() float64 {
+	throughputOnce.Do(func() {
+		const sampleSize = 256 * 1024
+		throughput = fallbackThroughput
+
+		key, err := cryptoutils.GenerateRandomBytesErr(32)
+		if err != nil {
+			return
+		}
+		sample, err := cryptoutils.GenerateRandomBytesErr(sampleSize)
+		if err != nil {
+			return
+		}
+
+		start := time.Now()
+		encrypted, err := cryptoutils.EncryptData(string(sample), key, nil)
+		if err == nil {
+			_, err = cryptoutils.DecryptData(encrypted, key, nil)
+		}
+		elapsed := time.Since(start)
+
+		if err != nil || elapsed <= 0 {
+			return
+		}
+		throughput = sampleSize / elapsed.Seconds()
+	})
+	return throughput
+}
+
+// EstimateRotationCost estimates the work RotateFieldKey would do for each
+// field without decrypting anything. Plaintext size is derived from the
+// ciphertext length; unknown fields are listed in Missing.
+func (scv *SecureCV) EstimateRotationCost(fields []string) RotationEstimate {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	estimate := RotationEstimate{}
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if seen[field] {
+			continue
+		}
+		seen[field] = true
+
+		encryptedData, exists := scv.encrypted[field]
+		if !exists {
+			estimate.Missing = append(estimate.Missing, field)
+			continue
+		}
+
+		if size := ciphertextLen(encryptedData); size > cryptoutils.TagSize {
+			estimate.PlaintextBytes += size - cryptoutils.TagSize
+		}
+		estimate.Fields++
+		estimate.KeysMinted++ // RotateFieldKey mints one key per field
+	}
+	sort.Strings(estimate.Missing)
+
+	seconds := float64(estimate.PlaintextBytes) / calibratedThroughput()
+	estimate.EstimatedTime = time.Duration(seconds * float64(time.Second))
+	return estimate
+}
+
+// PreviewLoad returns the key manifest LoadCV would produce for cvData in
+// mode, with placeholder key IDs and no key material. Nothing is encrypted
+// or stored.
+func (scv *SecureCV) PreviewLoad(cvData map[string]interface{}, mode string) (*models.KeyManifest, error) {
+	if cvData == nil {
+		return nil, fmt.Errorf("cv data is nil")
+	}
+	if mode != "single" && mode != "multi" {
+		return nil, fmt.Errorf("unknown mode %q", mode)
+	}
+
+	fields := make([]string, 0, len(cvData))
+	for field := range cvData {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	manifest := &models.KeyManifest{
+		Keys:     make(map[string]models.ShareableKey),
+		FieldMap: make(map[string]string, len(fields)),
+	}
+	for i, field := range fields {
+		keyID := "preview-key-1"
+		if mode == "multi" {
+			keyID = fmt.Sprintf("preview-key-%d", i+1)
+		}
+		manifest.FieldMap[field] = keyID
+
+		key := manifest.Keys[keyID]
+		key.KeyID = keyID
+		key.Fields = append(key.Fields, field)
+		manifest.Keys[keyID] = key
+	}
+	return manifest, nil
+}
@@ -0,0 +1,275 @@
+package securecv
+
+import (
+	"field_cipher/models"
+	"field_cipher/utils/cryptoutils"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FieldStatus classifies the outcome of an integrity check on one field
+type FieldStatus string
+
+const (
+	FieldOK         FieldStatus = "ok"
+	FieldRevoked    FieldStatus = "revoked"
+	FieldExpired    FieldStatus = "expired"
+	FieldMissingKey FieldStatus = "missing_key"
+	FieldCorrupted  FieldStatus = "corrupted"
+)
+
+// IntegrityError reports why a field failed its integrity check
+type IntegrityError struct {
+	Field  string
+	Status FieldStatus
+	Err    error
+}
+
+// Error implements the error interface
+func (ie *IntegrityError) Error() string {
+	return fmt.Sprintf("field '%s' %s: %v", ie.Field, ie.Status, ie.Err)
+}
+
+// Unwrap returns the underlying error
+func (ie *IntegrityError) Unwrap() error {
+	return ie.Err
+}
+
+// StatusOf returns the FieldStatus carried by a VerifyIntegrity error; a nil
+// error means the field is ok
+func StatusOf(err error) FieldStatus {
+	if err == nil {
+		return FieldOK
+	}
+	var ie *IntegrityError
+	if errors.As(err, &ie) {
+		return ie.Status
+	}
+	return FieldCorrupted
+}
+
+// VerifyIntegrity attempts to decrypt every field and reports the result per
+// field (nil when the field is intact). Revoked keys are reported separately
+// from authentication failures so intentional revocation can be told apart
+// from tampering. The second return value summarizes any failures.
+func (scv *SecureCV) VerifyIntegrity() (map[string]error, error) {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	results := make(map[string]error, len(scv.encrypted))
+	failed := 0
+	for field := range scv.encrypted {
+		results[field] = scv.verifyField(field)
+		if results[field] != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return results, fmt.Errorf("%d of %d fields failed integrity check", failed, len(results))
+	}
+	return results, nil
+}
+
+// verifyField checks one field; caller holds scv.mu
+func (scv *SecureCV) verifyField(field string) error {
+	node := scv.keys.GetNode(scv.fieldKeyMap[field])
+	if node == nil {
+		return &IntegrityError{Field: field, Status: FieldMissingKey, Err: fmt.Errorf("key not found")}
+	}
+	if node.Revoked {
+		return &IntegrityError{Field: field, Status: FieldRevoked, Err: fmt.Errorf("key %s revoked", node.KeyID)}
+	}
+	if node.PastExpiry() {
+		return &IntegrityError{Field: field, Status: FieldExpired, Err: fmt.Errorf("key %s expired", node.KeyID)}
+	}
+	if _, err := scv.decryptField(field); err != nil {
+		return &IntegrityError{Field: field, Status: FieldCorrupted, Err: err}
+	}
+	return nil
+}
+
+// Validate checks that the encrypted data and field key map list the same
+// fields and that every mapped key is in the key chain, reporting every
+// mismatch found. Nothing is decrypted.
+func (scv *SecureCV) Validate() error {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	problems := mapProblems(scv.encrypted, scv.fieldKeyMap)
+	for field, keyID := range scv.fieldKeyMap {
+		if _, exists := scv.encrypted[field]; exists && scv.keys.GetNode(keyID) == nil {
+			problems = append(problems, fmt.Sprintf("field '%s' references key %s, which is not in the key chain", field, keyID))
+		}
+	}
+	return problemsError(problems)
+}
+
+// mapProblems describes fields present in only one of the two maps
+func mapProblems(encrypted map[string]*models.EncryptedData, fieldKeyMap map[string]string) []string {
+	var problems []string
+	for field, data := range encrypted {
+		if data == nil {
+			problems = append(problems, fmt.Sprintf("field '%s' has no ciphertext", field))
+		}
+		if _, exists := fieldKeyMap[field]; !exists {
+			problems = append(problems, fmt.Sprintf("field '%s' has ciphertext but no key mapping", field))
+		}
+	}
+	for field := range fieldKeyMap {
+		if _, exists := encrypted[field]; !exists {
+			problems = append(problems, fmt.Sprintf("field '%s' has a key mapping but no ciphertext", field))
+		}
+	}
+	return problems
+}
+
+// fieldDigest is the hex SHA-256 over the sorted field names, each followed
+// by a NUL byte
+func fieldDigest(fields []string) string {
+	names := append([]string{}, fields...)
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, field := range names {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// checkManifestFields rejects a keys manifest whose field map does not cover
+// exactly the fields of the loaded encrypted CV, naming the difference. It
+// only applies once a CV carrying a field digest has been loaded. Caller
+// holds scv.mu.
+func (scv *SecureCV) checkManifestFields(manifest *models.KeyManifest) error {
+	if scv.fieldDigest == "" {
+		return nil
+	}
+	keyed := make([]string, 0, len(manifest.FieldMap))
+	for field := range manifest.FieldMap {
+		keyed = append(keyed, field)
+	}
+	if fieldDigest(keyed) == scv.fieldDigest {
+		return nil
+	}
+
+	var missing, unexpected []string
+	for field := range scv.encrypted {
+		if _, exists := manifest.FieldMap[field]; !exists {
+			missing = append(missing, field)
+		}
+	}
+	for field := range manifest.FieldMap {
+		if _, exists := scv.encrypted[field]; !exists {
+			unexpected = append(unexpected, field)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(unexpected)
+	return fmt.Errorf("keys file does not match the encrypted CV: missing fields [%s], unexpected fields [%s]",
+		strings.Join(missing, ", "), strings.Join(unexpected, ", "))
+}
+
+// encryptedFieldNames returns the field names of encrypted in no particular
+// order
+func encryptedFieldNames(encrypted map[string]*models.EncryptedData) []string {
+	names := make([]string, 0, len(encrypted))
+	for field := range encrypted {
+		names = append(names, field)
+	}
+	return names
+}
+
+// problemsError joins problems into one sorted error, or returns nil
+func problemsError(problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("%d consistency problems: %s", len(problems), strings.Join(problems, "; "))
+}
+
+// VerifyFieldHash decrypts a field and compares the SHA-256 of its plaintext
+// (strings as-is, other values as canonical JSON) against a published hash
+func (scv *SecureCV) VerifyFieldHash(field string, expected []byte) (bool, error) {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	value, err := scv.decryptField(field)
+	if err != nil {
+		return false, err
+	}
+
+	plaintext, err := cryptoutils.SerializeValue(value)
+	if err != nil {
+		return false, err
+	}
+
+	sum := sha256.Sum256(plaintext)
+	return cryptoutils.ConstantTimeKeyEqual(sum[:], expected), nil
+}
+
+// DiagnoseField walks the decryption path for a field and describes the
+// first problem found in plain language, or reports that the field is ok
+func (scv *SecureCV) DiagnoseField(field string) string {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	encryptedData, exists := scv.encrypted[field]
+	if !exists {
+		return fmt.Sprintf("field missing: '%s' is not in this CV (never loaded, removed, or misspelled)", field)
+	}
+
+	keyID, exists := scv.fieldKeyMap[field]
+	if !exists {
+		return fmt.Sprintf("no key mapping: '%s' has ciphertext but no entry in the field key map", field)
+	}
+
+	node := scv.keys.GetNode(keyID)
+	if node == nil {
+		return fmt.Sprintf("key not in chain: key %s for '%s' is not loaded (load the matching keys file)", keyID, field)
+	}
+	if node.Revoked {
+		return fmt.Sprintf("key revoked: key %s for '%s' has been revoked", keyID, field)
+	}
+	if node.PastExpiry() {
+		return fmt.Sprintf("key expired: key %s for '%s' is past its expiry time", keyID, field)
+	}
+
+	if encryptedData.Type != "partial" && encryptedData.Type != "stream" {
+		if problem := malformedCiphertext(encryptedData.Nonce, encryptedData.Ciphertext); problem != "" {
+			return fmt.Sprintf("ciphertext malformed: '%s' %s", field, problem)
+		}
+	}
+
+	if _, err := scv.decryptField(field); err != nil {
+		return fmt.Sprintf("authentication failed: '%s' does not decrypt with key %s (wrong key, wrong AAD, or tampered data): %v", field, keyID, err)
+	}
+	return fmt.Sprintf("ok: '%s' decrypts with key %s", field, keyID)
+}
+
+// malformedCiphertext describes structural problems with a stored record,
+// or returns "" when it is well formed
+func malformedCiphertext(nonceB64, ciphertextB64 string) string {
+	nonce, err := base64.StdEncoding.DecodeString(nonceB64)
+	if err != nil {
+		return fmt.Sprintf("has an invalid base64 nonce: %v", err)
+	}
+	if len(nonce) != cryptoutils.NonceSize {
+		return fmt.Sprintf("has a %d-byte nonce, expected %d", len(nonce), cryptoutils.NonceSize)
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(ciphertextB64)
+	if err != nil {
+		return fmt.Sprintf("has invalid base64 ciphertext: %v", err)
+	}
+	if len(ciphertext) < cryptoutils.TagSize {
+		return fmt.Sprintf("has a %d-byte ciphertext, shorter than the %d-byte tag", len(ciphertext), cryptoutils.TagSize)
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package securecv
+
+import (
+	"field_cipher/utils/cryptoutils"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+)
+
+// jsonContentType marks JWE payloads holding JSON rather than plain text
+const jsonContentType = "application/json"
+
+// ExportJWE decrypts a field and re-encrypts it for a recipient's RSA public
+// key as a compact JWE, so standard JOSE libraries can consume it. String
+// values are sent as-is; other values as JSON with cty "application/json".
+func (scv *SecureCV) ExportJWE(field string, recipientPub *rsa.PublicKey) (string, error) {
+	value, err := scv.GetField(field)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := cryptoutils.SerializeValue(value)
+	if err != nil {
+		return "", err
+	}
+
+	contentType := ""
+	if _, isString := value.(string); !isString {
+		contentType = jsonContentType
+	}
+
+	return cryptoutils.EncryptJWE(plaintext, contentType, recipientPub)
+}
+
+// ImportJWE decrypts a compact JWE produced by ExportJWE
+func ImportJWE(token string, recipientPriv *rsa.PrivateKey) (interface{}, error) {
+	plaintext, contentType, err := cryptoutils.DecryptJWE(token, recipientPriv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt JWE: %v", err)
+	}
+
+	if contentType != jsonContentType {
+		return string(plaintext), nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(plaintext, &value); err != nil {
+		return nil, fmt.Errorf("invalid JSON payload in JWE: %v", err)
+	}
+	return value, nil
+}
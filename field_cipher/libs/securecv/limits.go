@@ -0,0 +1,71 @@
+package securecv
+
+import (
+	"field_cipher/utils/cryptoutils"
+	"fmt"
+	"io"
+)
+
+// SetMaxFieldSize limits each field's serialized plaintext to maxBytes;
+// 0 or less (the default) means unlimited. Loads and SetField/AddField reject
+// larger fields with a "field exceeds max size" error before anything is
+// encrypted; fields already stored can still be rotated.
+func (scv *SecureCV) SetMaxFieldSize(maxBytes int) {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+	scv.maxFieldSize = maxBytes
+}
+
+// checkFieldSize rejects value if its plaintext is over the limit; caller
+// holds scv.mu
+func (scv *SecureCV) checkFieldSize(field string, value interface{}) error {
+	if scv.maxFieldSize <= 0 {
+		return nil
+	}
+	plaintext, err := cryptoutils.SerializeValue(value)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt field %s: %v", field, err)
+	}
+	return scv.checkFieldLength(field, len(plaintext))
+}
+
+// checkFieldLength rejects a plaintext of size bytes if it is over the
+// limit; caller holds scv.mu
+func (scv *SecureCV) checkFieldLength(field string, size int) error {
+	if scv.maxFieldSize > 0 && size > scv.maxFieldSize {
+		return fmt.Errorf("field exceeds max size: '%s' is %d bytes, limit %d", field, size, scv.maxFieldSize)
+	}
+	return nil
+}
+
+// checkFieldSizes checks every field in cvData, so a load fails before
+// encrypting any of them; caller holds scv.mu
+func (scv *SecureCV) checkFieldSizes(cvData map[string]interface{}) error {
+	if scv.maxFieldSize <= 0 {
+		return nil
+	}
+	for field, value := range cvData {
+		if err := scv.checkFieldSize(field, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// limitedReader fails once more than max bytes have been read
+type limitedReader struct {
+	r     io.Reader
+	field string
+	max   int
+	read  int
+}
+
+// Read implements io.Reader
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	n, err := lr.r.Read(p)
+	lr.read += n
+	if lr.read > lr.max {
+		return n, fmt.Errorf("field exceeds max size: '%s' is over %d bytes", lr.field, lr.max)
+	}
+	return n, err
+}
@@ -0,0 +1,149 @@
+package securecv
+
+import (
+	"field_cipher/models"
+	"field_cipher/utils/cryptoutils"
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// mergeField is a snapshot of one field taken from the instance being merged
+type mergeField struct {
+	encrypted      *models.EncryptedData
+	node           models.KeyNode
+	aad            []byte
+	classification string
+}
+
+// mergeUndo is a field's state before Merge overwrote it; a nil state means
+// the field did not exist
+type mergeUndo struct {
+	state          *FieldState
+	aad            []byte
+	classification string
+}
+
+// Merge imports other's encrypted fields, their keys and mappings into this
+// instance without decrypting anything. It fails without changes with a
+// *FieldExistsError if a field exists in both (unless SetAllowOverwrite, in
+// which case other's field wins), if a key ID is shared but the key bytes differ, or if any
+// of other's fields has no usable key. The current key is unchanged.
+func (scv *SecureCV) Merge(other *SecureCV) error {
+	if other == nil || other == scv {
+		return fmt.Errorf("cannot merge a SecureCV with itself or nil")
+	}
+
+	// Snapshot other first so the two locks are never held together
+	other.mu.RLock()
+	fields := make(map[string]mergeField, len(other.encrypted))
+	var unusable []string
+	for field, encryptedData := range other.encrypted {
+		node := other.keys.GetNode(other.fieldKeyMap[field])
+		if encryptedData == nil || node == nil || node.Revoked {
+			unusable = append(unusable, field)
+			continue
+		}
+		aad := other.aad
+		if fieldAAD, exists := other.fieldAAD[field]; exists {
+			aad = fieldAAD
+		}
+		data := *encryptedData
+		snapshot := *node
+		snapshot.KeyBytes = append([]byte{}, node.KeyBytes...)
+		fields[field] = mergeField{encrypted: &data, node: snapshot, aad: aad, classification: other.classification[field]}
+	}
+	other.mu.RUnlock()
+
+	if len(unusable) > 0 {
+		sort.Strings(unusable)
+		return fmt.Errorf("fields without a usable key in the merged CV: %s", strings.Join(unusable, ", "))
+	}
+
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	var collisions []string
+	for field, mf := range fields {
+		if !scv.allowOverwrite && scv.hasField(field) {
+			collisions = append(collisions, field)
+		}
+		if existing := scv.keys.GetNode(mf.node.KeyID); existing != nil && !cryptoutils.ConstantTimeKeyEqual(existing.KeyBytes, mf.node.KeyBytes) {
+			return fmt.Errorf("key ID %s exists in both CVs with different key bytes", mf.node.KeyID)
+		}
+	}
+	if len(collisions) > 0 {
+		sort.Strings(collisions)
+		return &FieldExistsError{Fields: collisions}
+	}
+
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	current := scv.keys.GetCurrentKey()
+	var imported []*models.KeyNode
+	before := make(map[string]*mergeUndo, len(names))
+	rollback := func(err error) error {
+		for field, undo := range before {
+			scv.restoreFieldState(field, undo.state)
+			if undo.aad != nil {
+				scv.fieldAAD[field] = undo.aad
+			} else {
+				delete(scv.fieldAAD, field)
+			}
+			if undo.classification != "" {
+				scv.classification[field] = undo.classification
+			} else {
+				delete(scv.classification, field)
+			}
+		}
+		scv.discardImported(imported, current)
+		return fmt.Errorf("merge rolled back: %v", err)
+	}
+
+	for _, field := range names {
+		mf := fields[field]
+		node := scv.keys.GetNode(mf.node.KeyID)
+		if node == nil {
+			var err error
+			if node, err = scv.keys.ImportKey(mf.node.KeyID, mf.node.KeyBytes); err != nil {
+				return rollback(err)
+			}
+			imported = append(imported, node)
+			node.Timestamp = mf.node.Timestamp
+			node.NonceCounter = mf.node.NonceCounter
+			node.ExpiresAt = mf.node.ExpiresAt
+			node.RotatedFrom = mf.node.RotatedFrom
+			node.UsageCount = mf.node.UsageCount
+			node.MaxUsage = mf.node.MaxUsage
+		}
+
+		undo := &mergeUndo{aad: scv.fieldAAD[field], classification: scv.classification[field]}
+		if scv.hasField(field) {
+			undo.state = &FieldState{KeyID: scv.fieldKeyMap[field], Encrypted: scv.encrypted[field]}
+		}
+		before[field] = undo
+
+		if !bytes.Equal(mf.aad, scv.aad) {
+			scv.fieldAAD[field] = mf.aad
+		} else {
+			delete(scv.fieldAAD, field)
+		}
+		if mf.classification != "" {
+			scv.classification[field] = mf.classification
+		} else {
+			delete(scv.classification, field)
+		}
+		if err := scv.storeField("merge", field, mf.encrypted, node); err != nil {
+			return rollback(err)
+		}
+	}
+	if current != nil && !current.Revoked {
+		return scv.keys.SetCurrentKey(current.KeyID)
+	}
+	return nil
+}
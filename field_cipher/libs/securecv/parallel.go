@@ -0,0 +1,94 @@
+package securecv
+
+import (
+	"field_cipher/models"
+	"field_cipher/utils/cryptoutils"
+	"fmt"
+	"sync"
+)
+
+// SetParallelism bounds the goroutines LoadCV uses to encrypt fields in
+// multi mode; 1 or less encrypts serially. Defaults to the number of CPUs.
+func (scv *SecureCV) SetParallelism(workers int) {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+	scv.parallelism = workers
+}
+
+// parallelJob is one field to encrypt under its own key
+type parallelJob struct {
+	field     string
+	value     interface{}
+	node      *models.KeyNode
+	aad       []byte
+	metadata  map[string]string
+	encrypted *models.EncryptedData
+	err       error
+}
+
+// loadCVParallel encrypts each field under its own key on a bounded worker
+// pool. Caller holds scv.mu for the whole load, so no reader sees the new
+// keys before their fields are stored; workers touch only their own nodes
+// and the key chain, which has its own lock.
+func (scv *SecureCV) loadCVParallel(cvData map[string]interface{}) error {
+	scv.logf("Loading %d CV fields in 'multi' mode with %d workers...", len(cvData), scv.parallelism)
+
+	jobs := make([]*parallelJob, 0, len(cvData))
+	for field, value := range cvData {
+		node, err := scv.keys.CreateKeyForFieldErr(field)
+		if err == nil {
+			node, err = scv.usableKey(node)
+		}
+		if err != nil {
+			return err
+		}
+		// Keep any metadata the field already carries, as encryptField does
+		var metadata map[string]string
+		if existing := scv.encrypted[field]; existing != nil {
+			metadata = existing.Metadata
+		}
+		jobs = append(jobs, &parallelJob{
+			field:    field,
+			value:    value,
+			node:     node,
+			aad:      cryptoutils.MetadataAAD(scv.aadFor(field), metadata),
+			metadata: metadata,
+		})
+	}
+	nonceMode := scv.nonceMode
+	workers := min(scv.parallelism, len(jobs))
+
+	queue := make(chan *parallelJob)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				job.encrypted, job.err = encryptValue(scv.keys, job.value, job.node, job.aad, nonceMode)
+				if job.err == nil {
+					attachMetadata(job.encrypted, job.metadata)
+				}
+			}
+		}()
+	}
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+
+	for _, job := range jobs {
+		if job.err != nil {
+			return fmt.Errorf("failed to encrypt field %s: %v", job.field, job.err)
+		}
+	}
+	for _, job := range jobs {
+		if err := scv.storeField("load", job.field, job.encrypted, job.node); err != nil {
+			return err
+		}
+	}
+
+	scv.logf("Encrypted %d fields with %d keys", len(cvData), scv.keys.Size())
+	return nil
+}
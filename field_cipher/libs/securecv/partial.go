@@ -0,0 +1,43 @@
+package securecv
+
+import (
+	"field_cipher/utils/cryptoutils"
+	"fmt"
+	"regexp"
+)
+
+// LoadPartialField stores a string field with only the parts matching
+// pattern encrypted; the rest stays readable in the saved file. GetField
+// reassembles the full value.
+func (scv *SecureCV) LoadPartialField(field, value string, pattern *regexp.Regexp) error {
+	return scv.LoadPartialFieldRegions(field, value, pattern.FindAllStringIndex(value, -1))
+}
+
+// LoadPartialFieldRegions is LoadPartialField with explicit [start, end)
+// byte offsets instead of a pattern
+func (scv *SecureCV) LoadPartialFieldRegions(field, value string, regions [][]int) error {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	if err := scv.checkNewField(field); err != nil {
+		return err
+	}
+	if err := scv.checkFieldLength(field, len(value)); err != nil {
+		return err
+	}
+	keyNode, err := scv.currentKey()
+	if err != nil {
+		return err
+	}
+	if keyNode, err = scv.usableKey(keyNode); err != nil {
+		return err
+	}
+
+	encryptedData, err := cryptoutils.EncryptRegions(value, regions, keyNode.KeyBytes, scv.aadFor(field))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt field %s: %v", field, err)
+	}
+	keyNode.UsageCount++
+
+	return scv.storeField("load", field, encryptedData, keyNode)
+}
@@ -0,0 +1,147 @@
+package securecv
+
+import (
+	"field_cipher/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Classification levels, lowest to highest. A caller may read a field when
+// their clearance is at or above the field's classification.
+const (
+	ClassPublic       = "public"
+	ClassInternal     = "internal"
+	ClassConfidential = "confidential"
+	ClassSecret       = "secret"
+)
+
+// classificationRank orders the levels
+var classificationRank = map[string]int{
+	ClassPublic:       0,
+	ClassInternal:     1,
+	ClassConfidential: 2,
+	ClassSecret:       3,
+}
+
+// PermissionError reports a read denied by GetFieldWithPolicy
+type PermissionError struct {
+	Field          string
+	Classification string
+	Clearance      string
+}
+
+// Error implements the error interface
+func (pe *PermissionError) Error() string {
+	return fmt.Sprintf("permission denied: field '%s' is %s, clearance %s is too low", pe.Field, pe.Classification, pe.Clearance)
+}
+
+// SetFieldClassification labels a field with a classification level; it is
+// saved with the encrypted CV. Unlabelled fields are public.
+func (scv *SecureCV) SetFieldClassification(field, level string) error {
+	if _, valid := classificationRank[level]; !valid {
+		return fmt.Errorf("unknown classification %q", level)
+	}
+
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	if _, exists := scv.encrypted[field]; !exists {
+		return fmt.Errorf("field '%s' not found", field)
+	}
+	if level == ClassPublic {
+		delete(scv.classification, field)
+	} else {
+		scv.classification[field] = level
+	}
+	scv.dirty.Store(true)
+	return nil
+}
+
+// GetFieldClassification returns a field's classification level
+func (scv *SecureCV) GetFieldClassification(field string) string {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+	return scv.classificationOf(field)
+}
+
+// classificationOf returns field's level, defaulting to public; caller holds
+// scv.mu
+func (scv *SecureCV) classificationOf(field string) string {
+	if level, exists := scv.classification[field]; exists {
+		return level
+	}
+	return ClassPublic
+}
+
+// GetFieldWithPolicy decrypts a field only if clearance is at or above its
+// classification, returning a *PermissionError otherwise
+func (scv *SecureCV) GetFieldWithPolicy(field, clearance string) (interface{}, error) {
+	rank, valid := classificationRank[clearance]
+	if !valid {
+		return nil, fmt.Errorf("unknown clearance %q", clearance)
+	}
+
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	if level := scv.classificationOf(field); rank < classificationRank[level] {
+		return nil, &PermissionError{Field: field, Classification: level, Clearance: clearance}
+	}
+
+	value, err := scv.decryptField(field)
+	if err == nil {
+		scv.recordAccess(field)
+	}
+	return value, err
+}
+
+// maskedValue replaces sensitive field values in DisplayMasked
+const maskedValue = "****"
+
+// DisplayMasked writes every field with its value to w for showing on a
+// shared screen. Fields classified above public are masked and never
+// decrypted; key bytes are never written, only short key IDs.
+func (scv *SecureCV) DisplayMasked(w io.Writer) error {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	fields := make([]string, 0, len(scv.encrypted))
+	for field := range scv.encrypted {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n%s\n", strings.Repeat("=", 70))
+	fmt.Fprintf(&b, "CV FIELDS (%d, sensitive values masked)\n", len(fields))
+	fmt.Fprintf(&b, "%s\n", strings.Repeat("=", 70))
+
+	for _, field := range fields {
+		keyID := models.ShortID(scv.fieldKeyMap[field], 8)
+		if level := scv.classificationOf(field); level != ClassPublic {
+			fmt.Fprintf(&b, "%s: %s [%s] (key %s...)\n", field, maskedValue, level, keyID)
+			continue
+		}
+
+		value, err := scv.decryptField(field)
+		if err != nil {
+			fmt.Fprintf(&b, "%s: <unavailable: %v> (key %s...)\n", field, err, keyID)
+			continue
+		}
+		text, isString := value.(string)
+		if !isString {
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("failed to format field '%s': %v", field, err)
+			}
+			text = string(encoded)
+		}
+		fmt.Fprintf(&b, "%s: %s (key %s...)\n", field, text, keyID)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
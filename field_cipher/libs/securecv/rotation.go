@@ -0,0 +1,338 @@
+package securecv
+
+import (
+	"field_cipher/models"
+	"field_cipher/utils/cryptoutils"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SetClock replaces the time source used for rotation bookkeeping, mainly so
+// tests can fake the passage of time
+func (scv *SecureCV) SetClock(now func() time.Time) {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+	scv.now = now
+}
+
+// SetMinRotationInterval sets the minimum time between rotations of any one
+// field; zero disables the check
+func (scv *SecureCV) SetMinRotationInterval(interval time.Duration) {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+	scv.minRotation = interval
+}
+
+// SetFieldMinRotationInterval overrides the minimum rotation interval for a
+// single field
+func (scv *SecureCV) SetFieldMinRotationInterval(field string, interval time.Duration) {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+	scv.fieldMinRotation[field] = interval
+}
+
+// checkRotationInterval rejects a rotation that comes too soon after the
+// previous one; caller holds scv.mu
+func (scv *SecureCV) checkRotationInterval(field string) error {
+	interval := scv.minRotation
+	if override, exists := scv.fieldMinRotation[field]; exists {
+		interval = override
+	}
+
+	last, rotated := scv.lastRotation[field]
+	if !rotated || interval <= 0 {
+		return nil
+	}
+
+	if elapsed := scv.now().Sub(last); elapsed < interval {
+		return fmt.Errorf("field '%s' rotated too recently: %v ago, minimum interval %v",
+			field, elapsed.Round(time.Second), interval)
+	}
+	return nil
+}
+
+// graceKey is the key a field was rotated off and when it stops being tried
+type graceKey struct {
+	keyID string
+	until time.Time
+}
+
+// SetRotationGrace keeps the key a field is rotated off usable for decrypting
+// that field for window after the rotation, so records still encrypted under
+// the old key (e.g. on replicas that have not caught up) keep working. GetField
+// tries the new key first. Zero, the default, disables the grace period.
+func (scv *SecureCV) SetRotationGrace(window time.Duration) {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+	scv.rotationGrace = window
+}
+
+// GraceKeyID returns the key field was rotated off while its grace window is
+// still open
+func (scv *SecureCV) GraceKeyID(field string) (string, bool) {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	grace, exists := scv.graceKeys[field]
+	if !exists || !scv.now().Before(grace.until) {
+		return "", false
+	}
+	return grace.keyID, true
+}
+
+// CleanupRotationGrace forgets old keys whose grace window has closed and
+// returns the affected fields. Expired windows are already ignored by
+// GetField; this only releases the bookkeeping.
+func (scv *SecureCV) CleanupRotationGrace() []string {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	var expired []string
+	for field, grace := range scv.graceKeys {
+		if !scv.now().Before(grace.until) {
+			expired = append(expired, field)
+			delete(scv.graceKeys, field)
+		}
+	}
+	sort.Strings(expired)
+	return expired
+}
+
+// startGrace opens a grace window for oldKeyID on field if one is
+// configured; caller holds scv.mu
+func (scv *SecureCV) startGrace(field, oldKeyID string) {
+	if scv.rotationGrace <= 0 {
+		return
+	}
+	scv.graceKeys[field] = graceKey{keyID: oldKeyID, until: scv.now().Add(scv.rotationGrace)}
+}
+
+// decryptWithGraceKey decrypts encryptedData with the key field was rotated
+// off, if its grace window is open and the key is still usable; caller holds
+// scv.mu
+func (scv *SecureCV) decryptWithGraceKey(field string, encryptedData *models.EncryptedData) (interface{}, bool) {
+	grace, exists := scv.graceKeys[field]
+	if !exists || !scv.now().Before(grace.until) {
+		return nil, false
+	}
+	node := scv.keys.GetNode(grace.keyID)
+	if node == nil || node.Revoked || node.PastExpiry() {
+		return nil, false
+	}
+
+	value, err := scv.decrypt(encryptedData, fieldKeyBytes(field, encryptedData, node.KeyBytes), scv.aadFor(field))
+	if err != nil {
+		return nil, false
+	}
+	scv.logf("Field '%s' decrypted with pre-rotation key %s... during its grace window", field, models.ShortID(grace.keyID, 8))
+	return value, true
+}
+
+// RotateIfOlderThan rotates field only when its key was created more than
+// maxAge ago, returning the key ID the field ends up under either way
+func (scv *SecureCV) RotateIfOlderThan(field string, maxAge time.Duration) (bool, string, error) {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	keyID, exists := scv.fieldKeyMap[field]
+	if !exists {
+		return false, "", fmt.Errorf("field '%s' not found", field)
+	}
+	node := scv.keys.GetNode(keyID)
+	if node == nil {
+		return false, "", fmt.Errorf("key %s for field '%s' not found", keyID, field)
+	}
+
+	if scv.now().Sub(node.GetCreationTime()) <= maxAge {
+		return false, keyID, nil
+	}
+
+	newKeyID, err := scv.rotateFieldKey(field)
+	if err != nil {
+		return false, keyID, err
+	}
+	return true, newKeyID, nil
+}
+
+// AutoRotateExpired rotates every field whose key was created more than
+// maxAge ago and returns the rotated fields, for cron-style maintenance.
+// Fields on revoked or missing keys are skipped; a field that fails to rotate
+// does not stop the others and is named in the returned error.
+func (scv *SecureCV) AutoRotateExpired(maxAge time.Duration) ([]string, error) {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	// Decide every field up front, before rotation adds fresh keys
+	var expired []string
+	for field, keyID := range scv.fieldKeyMap {
+		node := scv.keys.GetNode(keyID)
+		if node == nil || node.Revoked {
+			continue
+		}
+		if scv.now().Sub(node.GetCreationTime()) > maxAge {
+			expired = append(expired, field)
+		}
+	}
+	sort.Strings(expired)
+
+	var rotated []string
+	var errs []error
+	for _, field := range expired {
+		if _, err := scv.rotateFieldKey(field); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", field, err))
+			continue
+		}
+		rotated = append(rotated, field)
+	}
+	return rotated, errors.Join(errs...)
+}
+
+// RotateAllKeys re-encrypts every field under fresh keys and returns the new
+// key ID per field. Fields that shared a key share its replacement, so single
+// and grouped topologies survive. Every field is re-encrypted before anything
+// is stored, so a failure leaves the CV on its previous keys.
+func (scv *SecureCV) RotateAllKeys() (map[string]string, error) {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	fields := make([]string, 0, len(scv.encrypted))
+	for field := range scv.encrypted {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		if err := scv.checkRotationInterval(field); err != nil {
+			return nil, err
+		}
+	}
+
+	// Stage replacement keys outside the chain until every field succeeds
+	replacements := make(map[string]*models.KeyNode)
+	staged := make(map[string]*models.EncryptedData, len(fields))
+	for _, field := range fields {
+		plaintext, err := scv.decryptField(field)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt '%s' for rotation: %v", field, err)
+		}
+
+		oldKeyID := scv.fieldKeyMap[field]
+		node, exists := replacements[oldKeyID]
+		if !exists {
+			if node, err = scv.stagedKey(); err != nil {
+				return nil, err
+			}
+			replacements[oldKeyID] = node
+		}
+
+		if staged[field], err = scv.encryptField(field, plaintext, node); err != nil {
+			return nil, fmt.Errorf("failed to re-encrypt '%s': %v", field, err)
+		}
+	}
+
+	oldKeyIDs := make([]string, 0, len(replacements))
+	for oldKeyID := range replacements {
+		oldKeyIDs = append(oldKeyIDs, oldKeyID)
+	}
+	sort.Strings(oldKeyIDs)
+
+	previous := scv.keys.GetCurrentKey()
+	nodes := make(map[string]*models.KeyNode, len(replacements))
+	imported := make([]*models.KeyNode, 0, len(replacements))
+	for _, oldKeyID := range oldKeyIDs {
+		stagedNode := replacements[oldKeyID]
+		node, err := scv.keys.ImportKey(stagedNode.KeyID, stagedNode.KeyBytes)
+		if err != nil {
+			scv.discardImported(imported, previous)
+			return nil, err
+		}
+		imported = append(imported, node)
+		node.NonceCounter = stagedNode.NonceCounter
+		node.UsageCount = stagedNode.UsageCount
+		node.RotatedFrom = oldKeyID
+		if oldNode := scv.keys.GetNode(oldKeyID); oldNode != nil {
+			node.MaxUsage = oldNode.MaxUsage
+		}
+		nodes[oldKeyID] = node
+	}
+
+	before := make(map[string]*FieldState, len(fields))
+	newKeys := make(map[string]string, len(fields))
+	for _, field := range fields {
+		oldKeyID := scv.fieldKeyMap[field]
+		before[field] = &FieldState{KeyID: oldKeyID, Encrypted: scv.encrypted[field]}
+
+		if err := scv.storeField("rotate", field, staged[field], nodes[oldKeyID]); err != nil {
+			for restored, state := range before {
+				scv.restoreFieldState(restored, state)
+			}
+			scv.discardImported(imported, previous)
+			return nil, fmt.Errorf("rotation rolled back: %v", err)
+		}
+		newKeys[field] = nodes[oldKeyID].KeyID
+	}
+
+	now := scv.now()
+	for _, field := range fields {
+		scv.lastRotation[field] = now
+		scv.startGrace(field, before[field].KeyID)
+	}
+
+	scv.logf("Rotated %d fields onto %d new keys", len(fields), len(nodes))
+	return newKeys, nil
+}
+
+// stagedKey returns a fresh random key that is not yet in the chain, so
+// fields can be re-encrypted under it before anything is committed; caller
+// holds scv.mu
+func (scv *SecureCV) stagedKey() (*models.KeyNode, error) {
+	keyID, err := cryptoutils.GenerateRandomHexErr(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key ID: %v", err)
+	}
+	keyBytes, err := cryptoutils.GenerateRandomBytesErr(scv.keys.KeySize() / 8)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %v", err)
+	}
+	return &models.KeyNode{
+		KeyID:           keyID,
+		KeyBytes:        keyBytes,
+		EncryptedFields: make(map[string]bool),
+	}, nil
+}
+
+// discardImported removes keys imported by an operation that is being rolled
+// back and makes previous current again; caller holds scv.mu
+func (scv *SecureCV) discardImported(imported []*models.KeyNode, previous *models.KeyNode) {
+	for _, node := range imported {
+		scv.keys.RemoveKey(node.KeyID)
+	}
+	if previous != nil {
+		scv.keys.SetCurrentKey(previous.KeyID)
+	}
+}
+
+// GetKeyHistory returns the IDs of the keys field was previously encrypted
+// under, most recent first, by following each key's RotatedFrom link
+func (scv *SecureCV) GetKeyHistory(field string) ([]string, error) {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	keyID, exists := scv.fieldKeyMap[field]
+	if !exists {
+		return nil, fmt.Errorf("field '%s' not found", field)
+	}
+
+	history := make([]string, 0)
+	seen := map[string]bool{keyID: true}
+	for node := scv.keys.GetNode(keyID); node != nil && node.RotatedFrom != ""; node = scv.keys.GetNode(node.RotatedFrom) {
+		if seen[node.RotatedFrom] {
+			return nil, fmt.Errorf("rotation history for '%s' loops at key %s", field, node.RotatedFrom)
+		}
+		seen[node.RotatedFrom] = true
+		history = append(history, node.RotatedFrom)
+	}
+	return history, nil
+}
@@ -0,0 +1,52 @@
+package securecv
+
+import (
+	"field_cipher/models"
+	"field_cipher/utils/cryptoutils"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// sealedCVAAD binds sealed files to their purpose
+var sealedCVAAD = []byte("field_cipher sealed cv")
+
+// SaveEncryptedCVSealed saves the encrypted CV with the whole file encrypted
+// under key, so field names and structure are hidden at rest too
+func (scv *SecureCV) SaveEncryptedCVSealed(filename string, key []byte) error {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	plaintext, err := json.Marshal(scv.encryptedCV())
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %v", err)
+	}
+	sealed, err := cryptoutils.SealBytes(plaintext, key, sealedCVAAD)
+	if err != nil {
+		return fmt.Errorf("failed to seal CV: %v", err)
+	}
+	return scv.saveBytes(filename, sealed)
+}
+
+// LoadEncryptedCVSealed loads a CV saved by SaveEncryptedCVSealed. Keys need
+// to be loaded separately, as with LoadEncryptedCV.
+func (scv *SecureCV) LoadEncryptedCVSealed(filename string, key []byte) error {
+	sealed, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %v", filename, err)
+	}
+	plaintext, err := cryptoutils.OpenBytes(sealed, key, sealedCVAAD)
+	if err != nil {
+		return fmt.Errorf("failed to open sealed CV %s: %v", filename, err)
+	}
+
+	var data models.EncryptedCV
+	if err := json.Unmarshal(plaintext, &data); err != nil {
+		return fmt.Errorf("failed to parse sealed CV %s: %v", filename, err)
+	}
+
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	return scv.applyEncryptedCV(&data)
+}
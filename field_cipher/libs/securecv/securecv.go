@@ -5,28 +5,217 @@ import (
 	"field_cipher/models"
 	"field_cipher/utils/cryptoutils"
 	"field_cipher/utils/fileio"
+	"field_cipher/utils/logging"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"runtime"
 	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // SecureCV encrypts CV with per-field key management
 type SecureCV struct {
-	mu           sync.RWMutex
-	keys         *keychain.KeyChain
-	encrypted    map[string]*models.EncryptedData
-	fieldKeyMap  map[string]string
+	mu               sync.RWMutex
+	keys             *keychain.KeyChain
+	encrypted        map[string]*models.EncryptedData
+	fieldKeyMap      map[string]string
+	aad              []byte
+	fieldAAD         map[string][]byte
+	nonceMode        string
+	wal              WALStore
+	walSeq           uint64
+	autosaveCV       string
+	autosaveKeys     string
+	dirty            atomic.Bool
+	flushMu          sync.Mutex
+	now              func() time.Time
+	minRotation      time.Duration
+	fieldMinRotation map[string]time.Duration
+	lastRotation     map[string]time.Time
+	rotationGrace    time.Duration
+	graceKeys        map[string]graceKey // field -> key it was rotated off, still tried for decryption during the grace window
+	parallelism      int
+	statsMu          sync.Mutex
+	accessCount      map[string]int
+	lastAccess       map[string]int64
+	revokeOrphans    bool
+	wrappedKeys      string // file last saved or loaded with SaveKeysWrapped / LoadKeysWrapped
+	validateOnLoad   bool
+	fieldDigest      string // field digest of the last encrypted CV loaded; keys files must match it
+	cache            *decryptCache // nil unless EnableDecryptCache
+	classification   map[string]string
+	allowOverwrite   bool
+	useNumber        bool // decode nested numbers as json.Number (cryptoutils.DecryptDataUseNumber)
+	maxFieldSize     int // 0 means unlimited
+	logger           atomic.Pointer[logging.Logger]
 }
 
+// Nonce modes for field encryption
+const (
+	NonceRandom  = "random"  // fresh random nonce per encryption (default)
+	NonceDerived = "derived" // HKDF(key, counter) with a per-key counter
+)
+
 // NewSecureCV creates a new SecureCV instance
 func NewSecureCV() *SecureCV {
-	return &SecureCV{
-		keys:        keychain.NewKeyChain(),
-		encrypted:   make(map[string]*models.EncryptedData),
-		fieldKeyMap: make(map[string]string),
+	return NewSecureCVWithKeyChain(keychain.NewKeyChain())
+}
+
+// NewSecureCVWithKeyChain creates a SecureCV backed by an existing key chain,
+// e.g. one restored from backup
+func NewSecureCVWithKeyChain(keys *keychain.KeyChain) *SecureCV {
+	scv := &SecureCV{
+		keys:             keys,
+		encrypted:        make(map[string]*models.EncryptedData),
+		fieldKeyMap:      make(map[string]string),
+		fieldAAD:         make(map[string][]byte),
+		nonceMode:        NonceRandom,
+		now:              time.Now,
+		fieldMinRotation: make(map[string]time.Duration),
+		lastRotation:     make(map[string]time.Time),
+		graceKeys:        make(map[string]graceKey),
+		parallelism:      runtime.NumCPU(),
+		accessCount:      make(map[string]int),
+		lastAccess:       make(map[string]int64),
+		classification:   make(map[string]string),
 	}
+	scv.SetLogger(nil)
+	return scv
+}
+
+// SetLogger routes progress messages (loads, rotations, saves) to logger,
+// and sets the key chain's logger too. Nil restores the default, which
+// discards them; logging.Stdout prints them.
+func (scv *SecureCV) SetLogger(logger logging.Logger) {
+	logger = logging.OrNop(logger)
+	scv.logger.Store(&logger)
+	scv.keys.SetLogger(logger)
+}
+
+// logf sends one progress message to the logger
+func (scv *SecureCV) logf(format string, v ...interface{}) {
+	(*scv.logger.Load()).Printf(format, v...)
+}
+
+// SetKeySize sets the AES key size in bits (128, 192 or 256) for keys LoadCV
+// and rotation create from now on. Defaults to 256.
+func (scv *SecureCV) SetKeySize(bits int) error {
+	return scv.keys.SetKeySize(bits)
+}
+
+// SetNonceMode selects how nonces are generated. NonceDerived guarantees
+// unique nonces across instances sharing a key, provided each key's counter
+// is persisted (it is saved in the key manifest).
+func (scv *SecureCV) SetNonceMode(mode string) error {
+	if mode != NonceRandom && mode != NonceDerived {
+		return fmt.Errorf("unknown nonce mode %q", mode)
+	}
+
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+	scv.nonceMode = mode
+	return nil
+}
+
+// SetAAD sets associated data bound into every field's ciphertext, e.g. a
+// tenant ID or document version; fields only decrypt under the same AAD
+func (scv *SecureCV) SetAAD(aad []byte) {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+	scv.aad = aad
+	scv.cache.clear()
+}
+
+// SetFieldAAD overrides the associated data for a single field; nil removes
+// the override
+func (scv *SecureCV) SetFieldAAD(field string, aad []byte) {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	scv.cache.invalidate(field)
+	if aad == nil {
+		delete(scv.fieldAAD, field)
+		return
+	}
+	scv.fieldAAD[field] = aad
+}
+
+// aadFor returns the associated data for field, bound to the field name so
+// a ciphertext only decrypts in its own slot; caller holds scv.mu
+func (scv *SecureCV) aadFor(field string) []byte {
+	if aad, exists := scv.fieldAAD[field]; exists {
+		return cryptoutils.FieldAAD(field, aad)
+	}
+	return cryptoutils.FieldAAD(field, scv.aad)
+}
+
+// encryptField encrypts value for field under node's key, keeping any
+// metadata the field already carries; caller holds scv.mu
+func (scv *SecureCV) encryptField(field string, value interface{}, node *models.KeyNode) (*models.EncryptedData, error) {
+	var metadata map[string]string
+	if existing := scv.encrypted[field]; existing != nil {
+		metadata = existing.Metadata
+	}
+	return scv.encryptFieldWithMetadata(field, value, node, metadata)
+}
+
+// encryptFieldWithMetadata encrypts value for field with metadata bound into
+// the AAD; caller holds scv.mu
+func (scv *SecureCV) encryptFieldWithMetadata(field string, value interface{}, node *models.KeyNode, metadata map[string]string) (*models.EncryptedData, error) {
+	encryptedData, err := encryptValue(scv.keys, value, node, cryptoutils.MetadataAAD(scv.aadFor(field), metadata), scv.nonceMode)
+	if err != nil {
+		return nil, err
+	}
+	attachMetadata(encryptedData, metadata)
+	return encryptedData, nil
+}
+
+// attachMetadata copies the metadata bound into encryptedData's AAD onto the
+// record so it can be verified on decryption
+func attachMetadata(encryptedData *models.EncryptedData, metadata map[string]string) {
+	if len(metadata) > 0 {
+		encryptedData.Metadata = make(map[string]string, len(metadata))
+		for k, v := range metadata {
+			encryptedData.Metadata[k] = v
+		}
+	}
+}
+
+// encryptValue encrypts value under node's key with the given AAD and nonce
+// mode, rejecting nonces keys has seen before; callers must not share node
+// across goroutines in derived mode
+func encryptValue(keys *keychain.KeyChain, value interface{}, node *models.KeyNode, aad []byte, nonceMode string) (*models.EncryptedData, error) {
+	var encryptedData *models.EncryptedData
+	var err error
+	if nonceMode != NonceDerived {
+		encryptedData, err = cryptoutils.EncryptData(value, node.KeyBytes, aad)
+	} else {
+		var nonce []byte
+		if nonce, err = cryptoutils.DeriveNonce(node.KeyBytes, node.NonceCounter); err != nil {
+			return nil, err
+		}
+		node.NonceCounter++
+		encryptedData, err = cryptoutils.EncryptDataWithNonce(value, node.KeyBytes, nonce, aad)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(encryptedData.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	if err := keys.RecordNonce(node.KeyID, nonce); err != nil {
+		return nil, err
+	}
+	node.UsageCount++
+	return encryptedData, nil
 }
 
 // LoadCV loads and encrypts CV data
@@ -37,42 +226,464 @@ func (scv *SecureCV) LoadCV(cvData map[string]interface{}, mode string) error {
 	if cvData == nil {
 		return fmt.Errorf("cv data is nil")
 	}
+	if mode != "single" && mode != "multi" {
+		return fmt.Errorf("unknown mode %q", mode)
+	}
+	if err := scv.checkFieldSizes(cvData); err != nil {
+		return err
+	}
+	if mode == "multi" && scv.parallelism > 1 && len(cvData) > 1 {
+		return scv.loadCVParallel(cvData)
+	}
 
-	fmt.Printf("\nLoading %d CV fields in '%s' mode...\n", len(cvData), mode)
+	scv.logf("Loading %d CV fields in '%s' mode...", len(cvData), mode)
 
 	for field, value := range cvData {
+		keyNode, err := scv.loadKeyFor(field, mode)
+		if err != nil {
+			return err
+		}
+		if err := scv.loadField(field, value, keyNode); err != nil {
+			return err
+		}
+	}
+
+	scv.logf("Encrypted %d fields with %d keys", len(cvData), scv.keys.Size())
+	return nil
+}
+
+// FieldExistsError reports fields that an add or merge would overwrite
+type FieldExistsError struct {
+	Fields []string
+}
+
+// Error implements the error interface
+func (fe *FieldExistsError) Error() string {
+	return fmt.Sprintf("field already exists: %s", strings.Join(fe.Fields, ", "))
+}
+
+// SetAllowOverwrite lets SetField, Merge and the single-field loaders replace
+// existing fields instead of failing with a *FieldExistsError. AddField never
+// overwrites.
+func (scv *SecureCV) SetAllowOverwrite(enabled bool) {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+	scv.allowOverwrite = enabled
+}
+
+// SetUseNumber makes GetField return numbers nested in maps and slices as
+// json.Number rather than float64, so integers such as IDs survive exactly
+func (scv *SecureCV) SetUseNumber(enabled bool) {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+	scv.useNumber = enabled
+	scv.cache.clear()
+}
+
+// decrypt decrypts encryptedData with the CV's number decoding setting;
+// caller holds scv.mu
+func (scv *SecureCV) decrypt(encryptedData *models.EncryptedData, key []byte, aad []byte) (interface{}, error) {
+	if scv.useNumber {
+		return cryptoutils.DecryptDataUseNumber(encryptedData, key, aad)
+	}
+	return cryptoutils.DecryptData(encryptedData, key, aad)
+}
+
+// hasField reports whether field is stored; caller holds scv.mu
+func (scv *SecureCV) hasField(field string) bool {
+	_, exists := scv.encrypted[field]
+	return exists
+}
+
+// checkNewField rejects field if it exists and overwriting is off; caller
+// holds scv.mu
+func (scv *SecureCV) checkNewField(field string) error {
+	if !scv.allowOverwrite && scv.hasField(field) {
+		return &FieldExistsError{Fields: []string{field}}
+	}
+	return nil
+}
+
+// AddField encrypts and stores a new field, failing with a *FieldExistsError
+// if it already exists regardless of SetAllowOverwrite. Modes are as for
+// SetField.
+func (scv *SecureCV) AddField(field string, value interface{}, mode string) error {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	if scv.hasField(field) {
+		return &FieldExistsError{Fields: []string{field}}
+	}
+	return scv.setField(field, value, mode)
+}
+
+// SetField encrypts and stores one field after load. Existing fields are
+// only overwritten after SetAllowOverwrite(true). In multi mode the field
+// moves to its own new key (the same derived key on a seeded chain); in
+// single mode it uses the current key.
+func (scv *SecureCV) SetField(field string, value interface{}, mode string) error {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	if err := scv.checkNewField(field); err != nil {
+		return err
+	}
+	return scv.setField(field, value, mode)
+}
+
+// setField encrypts and stores field under the key mode selects; caller
+// holds scv.mu
+func (scv *SecureCV) setField(field string, value interface{}, mode string) error {
+	if err := scv.checkFieldSize(field, value); err != nil {
+		return err
+	}
+
+	if mode != "multi" && mode != "single" {
+		return fmt.Errorf("unknown mode %q", mode)
+	}
+	keyNode, err := scv.loadKeyFor(field, mode)
+	if err != nil {
+		return err
+	}
+	if keyNode, err = scv.usableKey(keyNode); err != nil {
+		return err
+	}
+
+	encryptedData, err := scv.encryptField(field, value, keyNode)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt field %s: %v", field, err)
+	}
+	return scv.storeField("set", field, encryptedData, keyNode)
+}
+
+// SetFieldMetadata re-encrypts field under its current key with metadata
+// (e.g. a "classification" label) bound into the AAD, so changing the stored
+// labels breaks decryption. Nil or empty metadata removes the labels.
+func (scv *SecureCV) SetFieldMetadata(field string, metadata map[string]string) error {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	value, err := scv.decryptField(field)
+	if err != nil {
+		return err
+	}
+
+	node := scv.keys.GetNode(scv.fieldKeyMap[field])
+	encryptedData, err := scv.encryptFieldWithMetadata(field, value, node, metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt field %s: %v", field, err)
+	}
+	return scv.storeField("metadata", field, encryptedData, node)
+}
+
+// GetFieldMetadata returns a field's metadata after confirming it has not
+// been tampered with
+func (scv *SecureCV) GetFieldMetadata(field string) (map[string]string, error) {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	if _, err := scv.decryptField(field); err != nil {
+		return nil, err
+	}
+
+	metadata := make(map[string]string, len(scv.encrypted[field].Metadata))
+	for k, v := range scv.encrypted[field].Metadata {
+		metadata[k] = v
+	}
+	return metadata, nil
+}
+
+// SetRevokeOrphanedKeys makes RemoveField revoke a key once it protects no
+// fields, unless it is the current key
+func (scv *SecureCV) SetRevokeOrphanedKeys(enabled bool) {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+	scv.revokeOrphans = enabled
+}
+
+// PruneOrphanKeys removes keys that no longer encrypt any field, such as the
+// old keys left behind by rotation, wiping their key material, and returns
+// how many were removed. The current key and keys still inside a rotation
+// grace window are kept.
+func (scv *SecureCV) PruneOrphanKeys() int {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	keep := make(map[string]bool)
+	if current := scv.keys.GetCurrentKey(); current != nil {
+		keep[current.KeyID] = true
+	}
+	for _, grace := range scv.graceKeys {
+		if scv.now().Before(grace.until) {
+			keep[grace.keyID] = true
+		}
+	}
+
+	pruned := 0
+	for _, node := range scv.keys.GetOrphanedKeys() {
+		if keep[node.KeyID] {
+			continue
+		}
+		if err := scv.keys.RemoveKey(node.KeyID); err != nil {
+			continue
+		}
+		scv.cache.invalidateKey(node.KeyID)
+		pruned++
+	}
+
+	if pruned > 0 {
+		scv.dirty.Store(true)
+		scv.logf("Pruned %d orphaned keys", pruned)
+	}
+	return pruned
+}
+
+// RemoveField deletes a field's ciphertext and its key mapping
+func (scv *SecureCV) RemoveField(field string) error {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	if _, exists := scv.encrypted[field]; !exists {
+		return fmt.Errorf("field '%s' not found", field)
+	}
+
+	seq, err := scv.walBeginOp("remove", field)
+	if err != nil {
+		return err
+	}
+
+	node := scv.keys.GetNode(scv.fieldKeyMap[field])
+	if node != nil {
+		delete(node.EncryptedFields, field)
+	}
+	delete(scv.encrypted, field)
+	delete(scv.fieldKeyMap, field)
+	delete(scv.lastRotation, field)
+	delete(scv.graceKeys, field)
+	delete(scv.classification, field)
+	scv.cache.invalidate(field)
+	scv.dirty.Store(true)
+
+	// The field is gone either way, so the op is committed before reporting
+	// a failure to revoke its key
+	var revokeErr error
+	if scv.revokeOrphans && node != nil && len(node.EncryptedFields) == 0 {
+		if current := scv.keys.GetCurrentKey(); current == nil || current.KeyID != node.KeyID {
+			revokeErr = scv.keys.RevokeKey(node.KeyID)
+		}
+	}
+
+	if err := scv.walCommitOp(seq, "remove", field); err != nil {
+		return err
+	}
+	if revokeErr != nil {
+		return fmt.Errorf("field '%s' removed but its key could not be revoked: %v", field, revokeErr)
+	}
+	return nil
+}
+
+// LoadCVWithGroups loads CV data with one key per named group of fields and
+// one key per ungrouped field. A field may belong to at most one group;
+// grouped fields missing from cvData are skipped with a warning.
+func (scv *SecureCV) LoadCVWithGroups(cvData map[string]interface{}, groups map[string][]string) error {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	if cvData == nil {
+		return fmt.Errorf("cv data is nil")
+	}
+	if err := scv.checkFieldSizes(cvData); err != nil {
+		return err
+	}
+
+	groupNames := make([]string, 0, len(groups))
+	for name := range groups {
+		groupNames = append(groupNames, name)
+	}
+	sort.Strings(groupNames)
+
+	groupOf := make(map[string]string)
+	for _, name := range groupNames {
+		for _, field := range groups[name] {
+			if other, exists := groupOf[field]; exists {
+				return fmt.Errorf("field '%s' is in both groups '%s' and '%s'", field, other, name)
+			}
+			groupOf[field] = name
+		}
+	}
+
+	scv.logf("Loading %d CV fields in %d groups...", len(cvData), len(groups))
+
+	for _, name := range groupNames {
 		var keyNode *models.KeyNode
-		
-		if mode == "multi" {
-			keyNode = scv.keys.CreateKey()
-		} else {
-			if scv.keys.GetCurrentKey() == nil {
-				keyNode = scv.keys.CreateKey()
-			} else {
-				keyNode = scv.keys.GetCurrentKey()
+		for _, field := range groups[name] {
+			value, exists := cvData[field]
+			if !exists {
+				scv.logf("Warning: field '%s' in group '%s' not in CV data, skipping", field, name)
+				continue
+			}
+			if keyNode == nil {
+				var err error
+				if keyNode, err = scv.keys.CreateKeyErr(); err != nil {
+					return err
+				}
+			}
+			if err := scv.loadField(field, value, keyNode); err != nil {
+				return err
 			}
 		}
+	}
 
-		// Encrypt field
-		encryptedData, err := cryptoutils.EncryptData(value, keyNode.KeyBytes)
+	for field, value := range cvData {
+		if _, grouped := groupOf[field]; grouped {
+			continue
+		}
+		keyNode, err := scv.keys.CreateKeyForFieldErr(field)
 		if err != nil {
-			return fmt.Errorf("failed to encrypt field %s: %v", field, err)
+			return err
+		}
+		if err := scv.loadField(field, value, keyNode); err != nil {
+			return err
 		}
-
-		scv.encrypted[field] = encryptedData
-		scv.fieldKeyMap[field] = keyNode.KeyID
-		keyNode.EncryptedFields[field] = true
 	}
 
-	fmt.Printf("Encrypted %d fields with %d keys\n", len(cvData), scv.keys.Size())
+	scv.logf("Encrypted %d fields with %d keys", len(cvData), scv.keys.Size())
 	return nil
 }
 
+// loadKeyFor returns the key LoadCV uses for field: its own key in multi
+// mode, the current key (created if needed) in single mode; caller holds
+// scv.mu
+func (scv *SecureCV) loadKeyFor(field, mode string) (*models.KeyNode, error) {
+	if mode == "multi" {
+		return scv.keys.CreateKeyForFieldErr(field)
+	}
+	return scv.currentKey()
+}
+
+// currentKey returns the chain's current key, creating one if the chain is
+// empty; caller holds scv.mu
+func (scv *SecureCV) currentKey() (*models.KeyNode, error) {
+	if keyNode := scv.keys.GetCurrentKey(); keyNode != nil {
+		return keyNode, nil
+	}
+	return scv.keys.CreateKeyErr()
+}
+
+// loadField encrypts and stores a field under keyNode, or the key replacing
+// it once its usage quota is spent; caller holds scv.mu
+func (scv *SecureCV) loadField(field string, value interface{}, keyNode *models.KeyNode) error {
+	keyNode, err := scv.usableKey(keyNode)
+	if err != nil {
+		return err
+	}
+	encryptedData, err := scv.encryptField(field, value, keyNode)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt field %s: %v", field, err)
+	}
+	return scv.storeField("load", field, encryptedData, keyNode)
+}
+
+// usableKey returns node, or a new key replacing it when node has used up its
+// encryption quota. The new key inherits the quota and becomes current.
+// Caller holds scv.mu.
+func (scv *SecureCV) usableKey(node *models.KeyNode) (*models.KeyNode, error) {
+	if !node.UsageExhausted() {
+		return node, nil
+	}
+	replacement, err := scv.keys.CreateKeyErr()
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate key %s past its quota: %v", node.KeyID, err)
+	}
+	replacement.RotatedFrom = node.KeyID
+	replacement.MaxUsage = node.MaxUsage
+	scv.logf("Key %s... reached its quota of %d encryptions; rotated to %s...",
+		models.ShortID(node.KeyID, 8), node.MaxUsage, models.ShortID(replacement.KeyID, 8))
+	return replacement, nil
+}
+
+// storeField records field's new ciphertext and key, moving the field off its
+// previous key node, logging to the WAL and marking the CV dirty; caller
+// holds scv.mu
+func (scv *SecureCV) storeField(op, field string, encryptedData *models.EncryptedData, node *models.KeyNode) error {
+	seq, err := scv.walBeginOp(op, field)
+	if err != nil {
+		return err
+	}
+
+	if oldNode := scv.keys.GetNode(scv.fieldKeyMap[field]); oldNode != nil {
+		delete(oldNode.EncryptedFields, field)
+	}
+	scv.cache.invalidate(field)
+	scv.encrypted[field] = encryptedData
+	scv.fieldKeyMap[field] = node.KeyID
+	node.EncryptedFields[field] = true
+	scv.dirty.Store(true)
+
+	return scv.walCommitOp(seq, op, field)
+}
+
 // GetField decrypts and retrieves field
 func (scv *SecureCV) GetField(field string) (interface{}, error) {
 	scv.mu.RLock()
 	defer scv.mu.RUnlock()
 
+	value, err := scv.decryptField(field)
+	if err == nil {
+		scv.recordAccess(field)
+	}
+	return value, err
+}
+
+// GetFields decrypts several fields under one read lock, returning the values
+// that decrypted and the errors for those that did not
+func (scv *SecureCV) GetFields(fields []string) (map[string]interface{}, map[string]error) {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	values := make(map[string]interface{}, len(fields))
+	errs := make(map[string]error)
+	for _, field := range fields {
+		value, err := scv.decryptField(field)
+		if err != nil {
+			errs[field] = err
+			continue
+		}
+		scv.recordAccess(field)
+		values[field] = value
+	}
+	return values, errs
+}
+
+// ForEachField decrypts every field in name order and passes it to fn, all
+// under one read lock so concurrent rotations cannot interleave. It stops at
+// the first decryption or callback error. fn must not call back into this
+// SecureCV.
+func (scv *SecureCV) ForEachField(fn func(field string, value interface{}) error) error {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	fields := make([]string, 0, len(scv.encrypted))
+	for field := range scv.encrypted {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		value, err := scv.decryptField(field)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt field '%s': %v", field, err)
+		}
+		scv.recordAccess(field)
+		if err := fn(field, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decryptField decrypts a single field; caller holds scv.mu
+func (scv *SecureCV) decryptField(field string) (interface{}, error) {
 	encryptedData, exists := scv.encrypted[field]
 	if !exists {
 		return nil, fmt.Errorf("field '%s' not found", field)
@@ -83,12 +694,61 @@ func (scv *SecureCV) GetField(field string) (interface{}, error) {
 		return nil, fmt.Errorf("no key found for field '%s'", field)
 	}
 
-	keyBytes, err := scv.keys.GetKeyBytes(keyID)
+	node := scv.keys.GetNode(keyID)
+	if node == nil {
+		return nil, fmt.Errorf("key %s for field '%s' not found", keyID, field)
+	}
+	if node.Revoked {
+		return nil, fmt.Errorf("field key revoked: field '%s' is encrypted under revoked key %s", field, keyID)
+	}
+	if node.PastExpiry() {
+		return nil, fmt.Errorf("field key expired: field '%s' is encrypted under expired key %s", field, keyID)
+	}
+
+	if value, hit := scv.cache.get(field, keyID, encryptedData); hit {
+		return value, nil
+	}
+	value, err := scv.decrypt(encryptedData, fieldKeyBytes(field, encryptedData, node.KeyBytes), scv.aadFor(field))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get key for field '%s': %v", field, err)
+		// Not cached, so the fallback stops working when the window closes
+		if value, ok := scv.decryptWithGraceKey(field, encryptedData); ok {
+			return value, nil
+		}
+		return nil, err
+	}
+	scv.cache.put(field, keyID, encryptedData, value)
+	return value, nil
+}
+
+// RevokeFieldKey revokes the key that encrypts field; every field sharing
+// that key becomes unreadable
+func (scv *SecureCV) RevokeFieldKey(field string) error {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	keyID, exists := scv.fieldKeyMap[field]
+	if !exists {
+		return fmt.Errorf("field '%s' not found", field)
 	}
+	return scv.revokeKey(keyID)
+}
 
-	return cryptoutils.DecryptData(encryptedData, keyBytes)
+// RevokeKey revokes a key by ID, including a shared key backing several fields
+func (scv *SecureCV) RevokeKey(keyID string) error {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	return scv.revokeKey(keyID)
+}
+
+// revokeKey revokes keyID and marks the CV dirty; caller holds scv.mu
+func (scv *SecureCV) revokeKey(keyID string) error {
+	if err := scv.keys.RevokeKey(keyID); err != nil {
+		return fmt.Errorf("failed to revoke key %s: %v", keyID, err)
+	}
+	scv.cache.invalidateKey(keyID)
+	scv.dirty.Store(true)
+	return nil
 }
 
 // RotateFieldKey rotates encryption key for specific field
@@ -96,6 +756,11 @@ func (scv *SecureCV) RotateFieldKey(field string) (string, error) {
 	scv.mu.Lock()
 	defer scv.mu.Unlock()
 
+	return scv.rotateFieldKey(field)
+}
+
+// rotateFieldKey re-encrypts field under a new key; caller holds scv.mu
+func (scv *SecureCV) rotateFieldKey(field string) (string, error) {
 	encryptedData, exists := scv.encrypted[field]
 	if !exists {
 		return "", fmt.Errorf("field '%s' not found", field)
@@ -107,39 +772,46 @@ func (scv *SecureCV) RotateFieldKey(field string) (string, error) {
 		return "", fmt.Errorf("no key found for field '%s'", field)
 	}
 
+	if err := scv.checkRotationInterval(field); err != nil {
+		return "", err
+	}
+
 	oldKeyBytes, err := scv.keys.GetKeyBytes(oldKeyID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get old key: %v", err)
 	}
 
 	// Decrypt with old key
-	plaintext, err := cryptoutils.DecryptData(encryptedData, oldKeyBytes)
+	plaintext, err := scv.decrypt(encryptedData, fieldKeyBytes(field, encryptedData, oldKeyBytes), scv.aadFor(field))
 	if err != nil {
 		return "", fmt.Errorf("failed to decrypt with old key: %v", err)
 	}
 
 	// Create new key
-	newKeyNode := scv.keys.CreateKey()
+	newKeyNode, err := scv.keys.CreateKeyErr()
+	if err != nil {
+		return "", fmt.Errorf("failed to create new key: %v", err)
+	}
+	newKeyNode.RotatedFrom = oldKeyID
+	if oldNode := scv.keys.GetNode(oldKeyID); oldNode != nil {
+		newKeyNode.MaxUsage = oldNode.MaxUsage
+	}
 
 	// Re-encrypt with new key
-	newEncryptedData, err := cryptoutils.EncryptData(plaintext, newKeyNode.KeyBytes)
+	newEncryptedData, err := scv.encryptField(field, plaintext, newKeyNode)
 	if err != nil {
 		return "", fmt.Errorf("failed to re-encrypt: %v", err)
 	}
 
 	// Update data structures
-	scv.encrypted[field] = newEncryptedData
-	scv.fieldKeyMap[field] = newKeyNode.KeyID
-
-	// Update tracking
-	oldNode := scv.keys.GetNode(oldKeyID)
-	if oldNode != nil {
-		delete(oldNode.EncryptedFields, field)
+	if err := scv.storeField("rotate", field, newEncryptedData, newKeyNode); err != nil {
+		return "", err
 	}
-	newKeyNode.EncryptedFields[field] = true
+	scv.lastRotation[field] = scv.now()
+	scv.startGrace(field, oldKeyID)
 
-	fmt.Printf("Rotated key for '%s': %s... -> %s...\n", 
-		field, oldKeyID[:8], newKeyNode.KeyID[:8])
+	scv.logf("Rotated key for '%s': %s... -> %s...", 
+		field, models.ShortID(oldKeyID, 8), models.ShortID(newKeyNode.KeyID, 8))
 	
 	return newKeyNode.KeyID, nil
 }
@@ -149,6 +821,11 @@ func (scv *SecureCV) GetShareableKey(field string) (*models.ShareableKey, error)
 	scv.mu.RLock()
 	defer scv.mu.RUnlock()
 
+	return scv.shareableKey(field)
+}
+
+// shareableKey builds the ShareableKey for field's key; caller holds scv.mu
+func (scv *SecureCV) shareableKey(field string) (*models.ShareableKey, error) {
 	keyID, exists := scv.fieldKeyMap[field]
 	if !exists {
 		return nil, fmt.Errorf("field '%s' not found", field)
@@ -166,9 +843,11 @@ func (scv *SecureCV) GetShareableKey(field string) (*models.ShareableKey, error)
 	sort.Strings(fields)
 
 	return &models.ShareableKey{
-		KeyID:  keyID,
-		Key:    base64.StdEncoding.EncodeToString(node.KeyBytes),
-		Fields: fields,
+		KeyID:       keyID,
+		Key:         base64.StdEncoding.EncodeToString(node.KeyBytes),
+		KeyHex:      hex.EncodeToString(node.KeyBytes),
+		Fields:      fields,
+		Fingerprint: cryptoutils.KeyFingerprint(node.KeyBytes),
 	}, nil
 }
 
@@ -177,59 +856,139 @@ func (scv *SecureCV) GetAllKeys() *models.KeyManifest {
 	scv.mu.RLock()
 	defer scv.mu.RUnlock()
 
+	return scv.keyManifest()
+}
+
+// keyManifest builds the full key manifest, listing keys in chain order in
+// Order; caller holds scv.mu
+func (scv *SecureCV) keyManifest() *models.KeyManifest {
 	manifest := &models.KeyManifest{
 		Keys:     make(map[string]models.ShareableKey),
 		FieldMap: make(map[string]string),
 	}
 
-	seenKeys := make(map[string]bool)
-
+	inUse := make(map[string]bool)
 	for field, keyID := range scv.fieldKeyMap {
 		manifest.FieldMap[field] = keyID
-		
-		if seenKeys[keyID] {
-			continue
+		inUse[keyID] = true
+	}
+
+	scv.keys.Iterate(func(_ int, node *models.KeyNode) bool {
+		if !inUse[node.KeyID] || node.Revoked {
+			return true
 		}
-		seenKeys[keyID] = true
 
-		node := scv.keys.GetNode(keyID)
-		if node != nil && !node.Revoked {
-			fields := make([]string, 0, len(node.EncryptedFields))
-			for f := range node.EncryptedFields {
-				fields = append(fields, f)
-			}
-			sort.Strings(fields)
+		fields := make([]string, 0, len(node.EncryptedFields))
+		for f := range node.EncryptedFields {
+			fields = append(fields, f)
+		}
+		sort.Strings(fields)
 
-			manifest.Keys[keyID] = models.ShareableKey{
-				KeyID:  keyID,
-				Key:    base64.StdEncoding.EncodeToString(node.KeyBytes),
-				Fields: fields,
-			}
+		manifest.Keys[node.KeyID] = models.ShareableKey{
+			KeyID:        node.KeyID,
+			Key:          base64.StdEncoding.EncodeToString(node.KeyBytes),
+			KeyHex:       hex.EncodeToString(node.KeyBytes),
+			Fields:       fields,
+			NonceCounter: node.NonceCounter,
+			Fingerprint:  cryptoutils.KeyFingerprint(node.KeyBytes),
+			Timestamp:    node.Timestamp,
+			ExpiresAt:    node.ExpiresAt,
+			RotatedFrom:  node.RotatedFrom,
+			UsageCount:   node.UsageCount,
+			MaxUsage:     node.MaxUsage,
 		}
-	}
+		manifest.Order = append(manifest.Order, node.KeyID)
+		return true
+	})
 
 	return manifest
 }
 
+// CVTopology describes which fields exist and which share keys, without any
+// key material
+type CVTopology struct {
+	Fields map[string]string   `json:"fields"`
+	Groups map[string][]string `json:"groups"`
+}
+
+// Topology returns the field-to-key structure of the CV for review
+func (scv *SecureCV) Topology() *CVTopology {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	topology := &CVTopology{
+		Fields: make(map[string]string, len(scv.fieldKeyMap)),
+		Groups: make(map[string][]string),
+	}
+
+	for field, keyID := range scv.fieldKeyMap {
+		topology.Fields[field] = keyID
+		topology.Groups[keyID] = append(topology.Groups[keyID], field)
+	}
+	for _, fields := range topology.Groups {
+		sort.Strings(fields)
+	}
+
+	return topology
+}
+
 // SaveEncryptedCV saves encrypted CV to file
 func (scv *SecureCV) SaveEncryptedCV(filename string) error {
 	scv.mu.RLock()
 	defer scv.mu.RUnlock()
 
+	return scv.saveEncryptedCV(filename)
+}
+
+// saveEncryptedCV writes the encrypted CV; caller holds scv.mu
+func (scv *SecureCV) saveEncryptedCV(filename string) error {
+	return scv.saveJSON(filename, scv.encryptedCV())
+}
+
+// encryptedCV builds the saved form of the CV; caller holds scv.mu
+func (scv *SecureCV) encryptedCV() *models.EncryptedCV {
 	data := &models.EncryptedCV{
-		EncryptedData: scv.encrypted,
-		FieldKeyMap:   scv.fieldKeyMap,
+		EncryptedData:   scv.encrypted,
+		FieldKeyMap:     scv.fieldKeyMap,
+		Classifications: scv.classification,
 	}
 	data.Metadata.TotalFields = len(scv.encrypted)
 	data.Metadata.TotalKeys = scv.keys.Size()
+	data.Metadata.FieldDigest = fieldDigest(encryptedFieldNames(scv.encrypted))
+	return data
+}
+
+// saveJSON writes v to filename and logs it
+func (scv *SecureCV) saveJSON(filename string, v interface{}) error {
+	if err := fileio.SaveJSON(filename, v); err != nil {
+		return err
+	}
+	scv.logf("Saved data to %s", filename)
+	return nil
+}
+
+// saveBytes writes data to filename and logs it
+func (scv *SecureCV) saveBytes(filename string, data []byte) error {
+	if err := fileio.SaveBytes(filename, data); err != nil {
+		return err
+	}
+	scv.logf("Saved data to %s", filename)
+	return nil
+}
 
-	return fileio.SaveJSON(filename, data)
+// loadJSON reads filename into result and logs it
+func (scv *SecureCV) loadJSON(filename string, result interface{}) error {
+	if err := fileio.LoadJSON(filename, result); err != nil {
+		return err
+	}
+	scv.logf("Loaded data from %s", filename)
+	return nil
 }
 
 // SaveKeys saves key manifest to file
 func (scv *SecureCV) SaveKeys(filename string) error {
 	manifest := scv.GetAllKeys()
-	return fileio.SaveJSON(filename, manifest)
+	return scv.saveJSON(filename, manifest)
 }
 
 // LoadEncryptedCV loads encrypted CV from file
@@ -238,18 +997,284 @@ func (scv *SecureCV) LoadEncryptedCV(filename string) error {
 	defer scv.mu.Unlock()
 
 	var data models.EncryptedCV
-	if err := fileio.LoadJSON(filename, &data); err != nil {
+	if err := scv.loadJSON(filename, &data); err != nil {
 		return err
 	}
 
+	return scv.applyEncryptedCV(&data)
+}
+
+// SetValidateOnLoad makes LoadEncryptedCV reject files whose encrypted data
+// and field key map disagree. Keys are loaded separately, so call Validate
+// after loading them to also check key references.
+func (scv *SecureCV) SetValidateOnLoad(enabled bool) {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+	scv.validateOnLoad = enabled
+}
+
+// applyEncryptedCV replaces the CV's fields with a loaded EncryptedCV; caller
+// holds scv.mu
+func (scv *SecureCV) applyEncryptedCV(data *models.EncryptedCV) error {
+	if scv.validateOnLoad {
+		if err := problemsError(mapProblems(data.EncryptedData, data.FieldKeyMap)); err != nil {
+			return fmt.Errorf("invalid encrypted CV: %v", err)
+		}
+	}
+	if data.Metadata.FieldDigest != "" && fieldDigest(encryptedFieldNames(data.EncryptedData)) != data.Metadata.FieldDigest {
+		return fmt.Errorf("invalid encrypted CV: fields do not match the field digest")
+	}
+
+	scv.cache.clear()
 	scv.encrypted = data.EncryptedData
 	scv.fieldKeyMap = data.FieldKeyMap
+	if scv.encrypted == nil {
+		scv.encrypted = make(map[string]*models.EncryptedData)
+	}
+	if scv.fieldKeyMap == nil {
+		scv.fieldKeyMap = make(map[string]string)
+	}
+	scv.classification = data.Classifications
+	if scv.classification == nil {
+		scv.classification = make(map[string]string)
+	}
+	scv.fieldDigest = data.Metadata.FieldDigest
 	
 	// Note: Keys need to be loaded separately for security
-	fmt.Printf("Loaded encrypted CV with %d fields\n", data.Metadata.TotalFields)
+	scv.logf("Loaded encrypted CV with %d fields", data.Metadata.TotalFields)
+	return nil
+}
+
+// LoadKeys reads a key manifest saved by SaveKeys and imports its key
+// material into the key chain
+func (scv *SecureCV) LoadKeys(filename string) error {
+	var manifest models.KeyManifest
+	if err := scv.loadJSON(filename, &manifest); err != nil {
+		return err
+	}
+
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	if err := scv.checkManifestFields(&manifest); err != nil {
+		return err
+	}
+	return scv.importManifest(&manifest)
+}
+
+// Restore loads an encrypted CV and its keys file and reconciles the two, so
+// every field is ready for GetField
+func (scv *SecureCV) Restore(cvFile, keysFile string) error {
+	if err := scv.LoadEncryptedCV(cvFile); err != nil {
+		return err
+	}
+	if err := scv.LoadKeys(keysFile); err != nil {
+		return err
+	}
+
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	return scv.reconcile()
+}
+
+// LoadPair loads an encrypted CV together with its key manifest and
+// reconciles the two, so the returned instance is ready for GetField
+func LoadPair(cvFile, keysFile string) (*SecureCV, error) {
+	scv := NewSecureCV()
+	if err := scv.Restore(cvFile, keysFile); err != nil {
+		return nil, err
+	}
+	return scv, nil
+}
+
+// importManifest adds the manifest's key material to the key chain, with
+// its saved timestamps and revocations, and wires each key to the fields that
+// reference it; caller holds scv.mu
+func (scv *SecureCV) importManifest(manifest *models.KeyManifest) error {
+	keyIDs := manifest.Order
+	if len(keyIDs) != len(manifest.Keys) {
+		keyIDs = make([]string, 0, len(manifest.Keys))
+		for keyID := range manifest.Keys {
+			keyIDs = append(keyIDs, keyID)
+		}
+		sort.Strings(keyIDs)
+	}
+
+	for _, keyID := range keyIDs {
+		if manifest.Keys[keyID].Wrapped != nil {
+			return fmt.Errorf("key %s is wrapped under a master key; use LoadKeysWrapped", keyID)
+		}
+		keyBytes, err := manifest.Keys[keyID].DecodeKey()
+		if err != nil {
+			return fmt.Errorf("invalid key material for %s: %v", keyID, err)
+		}
+		if err := cryptoutils.ValidateKey(keyBytes); err != nil {
+			return fmt.Errorf("invalid key %s: %v", keyID, err)
+		}
+		if err := checkFingerprint(manifest.Keys[keyID], keyBytes); err != nil {
+			return err
+		}
+		node, err := scv.keys.ImportKey(keyID, keyBytes)
+		if err != nil {
+			return err
+		}
+		restoreKeyMetadata(node, manifest.Keys[keyID])
+	}
+	// A revoked key cannot be made current again; keep the chain's choice
+	if manifest.Current != "" && !manifest.Keys[manifest.Current].Revoked {
+		if err := scv.keys.SetCurrentKey(manifest.Current); err != nil {
+			return fmt.Errorf("cannot restore current key %s: %v", manifest.Current, err)
+		}
+	}
+
+	for _, fieldMap := range []map[string]string{manifest.FieldMap, scv.fieldKeyMap} {
+		for field, keyID := range fieldMap {
+			if node := scv.keys.GetNode(keyID); node != nil {
+				node.EncryptedFields[field] = true
+			}
+		}
+	}
+	return nil
+}
+
+// restoreKeyMetadata copies saved metadata onto an imported key. Manifests
+// without timestamps keep the import time. Revoked keys are zeroized as
+// RevokeKey would, but keep their original timestamp so CleanupRevokedKeys
+// ages them correctly.
+func restoreKeyMetadata(node *models.KeyNode, shareable models.ShareableKey) {
+	node.NonceCounter = shareable.NonceCounter
+	node.ExpiresAt = shareable.ExpiresAt
+	node.RotatedFrom = shareable.RotatedFrom
+	node.UsageCount = shareable.UsageCount
+	node.MaxUsage = shareable.MaxUsage
+	if shareable.Timestamp != 0 {
+		node.Timestamp = shareable.Timestamp
+	}
+	if shareable.Revoked {
+		node.Revoked = true
+		cryptoutils.Zeroize(node.KeyBytes)
+	}
+}
+
+// reconcile checks that every field has a key and that the keys actually
+// decrypt the data; caller holds scv.mu
+func (scv *SecureCV) reconcile() error {
+	fields := make([]string, 0, len(scv.encrypted))
+	for field := range scv.encrypted {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		keyID, exists := scv.fieldKeyMap[field]
+		if !exists {
+			return fmt.Errorf("no key mapping for field '%s'", field)
+		}
+		if scv.keys.GetNode(keyID) == nil {
+			return fmt.Errorf("key %s for field '%s' missing from keys file", keyID, field)
+		}
+	}
+
+	// One successful decryption proves the keys belong to this CV
+	if len(fields) > 0 {
+		if _, err := scv.decryptField(fields[0]); err != nil {
+			return fmt.Errorf("sanity check failed on field '%s': %v", fields[0], err)
+		}
+	}
 	return nil
 }
 
+// OriginalJSON decrypts every field and returns the CV as JSON matching the
+// originally loaded data
+func (scv *SecureCV) OriginalJSON() ([]byte, error) {
+	cvData, err := scv.decryptAll()
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(cvData, "", "  ")
+}
+
+// decryptAll decrypts every field under one read lock
+func (scv *SecureCV) decryptAll() (map[string]interface{}, error) {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	cvData := make(map[string]interface{}, len(scv.encrypted))
+	for field := range scv.encrypted {
+		value, err := scv.decryptField(field)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt field '%s': %v", field, err)
+		}
+		cvData[field] = value
+	}
+	return cvData, nil
+}
+
+// KeyChain returns the underlying key chain
+func (scv *SecureCV) KeyChain() *keychain.KeyChain {
+	return scv.keys
+}
+
+// FieldsByCreationOrder returns fields ordered by their key's creation time,
+// ties broken by field name
+func (scv *SecureCV) FieldsByCreationOrder() []string {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	created := make(map[string]int64, len(scv.encrypted))
+	fields := make([]string, 0, len(scv.encrypted))
+	for field := range scv.encrypted {
+		fields = append(fields, field)
+		if node := scv.keys.GetNode(scv.fieldKeyMap[field]); node != nil {
+			created[field] = node.Timestamp
+		}
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		if created[fields[i]] != created[fields[j]] {
+			return created[fields[i]] < created[fields[j]]
+		}
+		return fields[i] < fields[j]
+	})
+	return fields
+}
+
+// FindFields returns the sorted field names matching a glob pattern
+// (filepath.Match syntax, e.g. "project_*"), without decrypting anything. A
+// malformed pattern matches nothing.
+func (scv *SecureCV) FindFields(pattern string) []string {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	var fields []string
+	for field := range scv.encrypted {
+		if matched, err := filepath.Match(pattern, field); err == nil && matched {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// FieldsForKey returns the sorted fields encrypted under keyID, e.g. to see
+// what revoking a shared key would make unreadable
+func (scv *SecureCV) FieldsForKey(keyID string) ([]string, error) {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	node := scv.keys.GetNode(keyID)
+	if node == nil {
+		return nil, fmt.Errorf("key %s not found", keyID)
+	}
+	fields := make([]string, 0, len(node.EncryptedFields))
+	for field := range node.EncryptedFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
 // DisplayKeys displays the current key chain
 func (scv *SecureCV) DisplayKeys() {
 	scv.keys.Display()
@@ -268,10 +1293,29 @@ func (scv *SecureCV) GetStats() map[string]interface{} {
 	for k, v := range keyStats {
 		stats[k] = v
 	}
+	if scv.cache != nil {
+		stats["cache_hits"], stats["cache_misses"], stats["cache_entries"] = scv.cache.stats()
+	}
 	
 	return stats
 }
 
+// Stats returns the field and key counts as a typed struct; GetStats keeps
+// the map form, with cache counters
+func (scv *SecureCV) Stats() models.CVStats {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	keyStats := scv.keys.GetKeyStats()
+	return models.CVStats{
+		TotalFields:  len(scv.encrypted),
+		TotalKeys:    keyStats["total_keys"].(int),
+		ActiveKeys:   keyStats["active_keys"].(int),
+		RevokedKeys:  keyStats["revoked_keys"].(int),
+		CurrentKeyID: keyStats["current_key_id"].(string),
+	}
+}
+
 // ExportField exports a specific field with its key
 func (scv *SecureCV) ExportField(field string) (map[string]interface{}, error) {
 	scv.mu.RLock()
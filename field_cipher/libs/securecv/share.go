@@ -0,0 +1,272 @@
+package securecv
+
+import (
+	"field_cipher/models"
+	"field_cipher/utils/cryptoutils"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ExportFieldBundle packages a field's ciphertext with a key scoped to just
+// that field, for a recipient who only has this package and no SecureCV
+func (scv *SecureCV) ExportFieldBundle(field string) (*models.FieldBundle, error) {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	encryptedData, exists := scv.encrypted[field]
+	if !exists {
+		return nil, fmt.Errorf("field '%s' not found", field)
+	}
+
+	keyID := scv.fieldKeyMap[field]
+	node := scv.keys.GetNode(keyID)
+	if node == nil || node.Revoked {
+		return nil, fmt.Errorf("key not available or revoked")
+	}
+
+	return &models.FieldBundle{
+		Field:     field,
+		Encrypted: encryptedData,
+		Key: &models.ShareableKey{
+			KeyID:       keyID,
+			Key:         base64.StdEncoding.EncodeToString(node.KeyBytes),
+			KeyHex:      hex.EncodeToString(node.KeyBytes),
+			Fields:      []string{field},
+			Fingerprint: cryptoutils.KeyFingerprint(node.KeyBytes),
+		},
+	}, nil
+}
+
+// ShareFieldWith shares field with several recipients at once, wrapping its
+// key under each recipient's KEK (cryptoutils.WrapForRecipients). Anyone
+// holding the key sees every field encrypted under it, as with
+// GetShareableKey.
+func (scv *SecureCV) ShareFieldWith(field string, recipients map[string][]byte) (*models.RecipientShare, error) {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	encryptedData, exists := scv.encrypted[field]
+	if !exists {
+		return nil, fmt.Errorf("field '%s' not found", field)
+	}
+
+	keyID := scv.fieldKeyMap[field]
+	node := scv.keys.GetNode(keyID)
+	if node == nil || node.Revoked {
+		return nil, fmt.Errorf("key not available or revoked")
+	}
+
+	wrapped, err := cryptoutils.WrapForRecipients(node.KeyBytes, recipients)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RecipientShare{
+		Field:      field,
+		KeyID:      keyID,
+		Encrypted:  encryptedData,
+		Recipients: wrapped,
+	}, nil
+}
+
+// OpenRecipientShare decrypts a share from ShareFieldWith as recipientID,
+// unwrapping the field key with that recipient's KEK
+func OpenRecipientShare(share *models.RecipientShare, recipientID string, kek []byte) (interface{}, error) {
+	if share == nil {
+		return nil, fmt.Errorf("share is nil")
+	}
+	wrapped, exists := share.Recipients[recipientID]
+	if !exists {
+		return nil, fmt.Errorf("recipient %s not in share", recipientID)
+	}
+
+	keyBytes, err := cryptoutils.UnwrapKey(wrapped, kek)
+	if err != nil {
+		return nil, err
+	}
+	defer cryptoutils.Zeroize(keyBytes)
+
+	return DecryptField(share.Encrypted, &models.ShareableKey{
+		KeyID:  share.KeyID,
+		Key:    base64.StdEncoding.EncodeToString(keyBytes),
+		Fields: []string{share.Field},
+	})
+}
+
+// checkFingerprint rejects key bytes that do not match the fingerprint sent
+// with them; keys without a fingerprint are accepted
+func checkFingerprint(shareable models.ShareableKey, keyBytes []byte) error {
+	if shareable.Fingerprint != "" && !cryptoutils.ConstantTimeKeyEqual([]byte(shareable.Fingerprint), []byte(cryptoutils.KeyFingerprint(keyBytes))) {
+		return fmt.Errorf("key %s does not match its fingerprint %s", shareable.KeyID, shareable.Fingerprint)
+	}
+	return nil
+}
+
+// RotateAndShare rotates field's key and returns the new ShareableKey under
+// the same lock, so no other rotation can slip in between and the key handed
+// out is the one the field is now encrypted under
+func (scv *SecureCV) RotateAndShare(field string) (*models.ShareableKey, error) {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	if _, err := scv.rotateFieldKey(field); err != nil {
+		return nil, err
+	}
+	return scv.shareableKey(field)
+}
+
+// DecryptField decrypts a shared ciphertext with a ShareableKey. The field
+// name is bound into the ciphertext, so each field listed on the key is tried
+// in turn. Fields encrypted with caller AAD (SetAAD) cannot be decrypted here.
+func DecryptField(encrypted *models.EncryptedData, shareable *models.ShareableKey) (interface{}, error) {
+	if encrypted == nil || shareable == nil {
+		return nil, fmt.Errorf("encrypted data and key are required")
+	}
+
+	keyBytes, err := shareable.DecodeKey()
+	if err != nil {
+		return nil, fmt.Errorf("invalid key material for %s: %v", shareable.KeyID, err)
+	}
+	if err := cryptoutils.ValidateKey(keyBytes); err != nil {
+		return nil, fmt.Errorf("invalid key %s: %v", shareable.KeyID, err)
+	}
+	if err := checkFingerprint(*shareable, keyBytes); err != nil {
+		return nil, err
+	}
+
+	for _, field := range shareable.Fields {
+		if value, err := cryptoutils.DecryptData(encrypted, fieldKeyBytes(field, encrypted, keyBytes), cryptoutils.FieldAAD(field, nil)); err == nil {
+			return value, nil
+		}
+	}
+	return nil, fmt.Errorf("key %s does not decrypt this data for any of its fields", shareable.KeyID)
+}
+
+// ExportFields returns the ciphertext of just the requested fields plus a
+// manifest holding exactly the keys needed to decrypt them. Shared keys
+// appear once and list only the requested fields.
+func (scv *SecureCV) ExportFields(fields []string) (*models.KeyManifest, map[string]*models.EncryptedData, error) {
+	scv.mu.RLock()
+	defer scv.mu.RUnlock()
+
+	manifest := &models.KeyManifest{
+		Keys:     make(map[string]models.ShareableKey),
+		FieldMap: make(map[string]string, len(fields)),
+	}
+	encrypted := make(map[string]*models.EncryptedData, len(fields))
+
+	for _, field := range fields {
+		encryptedData, exists := scv.encrypted[field]
+		if !exists {
+			return nil, nil, fmt.Errorf("field '%s' not found", field)
+		}
+
+		keyID := scv.fieldKeyMap[field]
+		node := scv.keys.GetNode(keyID)
+		if node == nil || node.Revoked {
+			return nil, nil, fmt.Errorf("key for field '%s' not available or revoked", field)
+		}
+
+		shareable, exists := manifest.Keys[keyID]
+		if !exists {
+			shareable = models.ShareableKey{
+				KeyID:        keyID,
+				Key:          base64.StdEncoding.EncodeToString(node.KeyBytes),
+				KeyHex:       hex.EncodeToString(node.KeyBytes),
+				NonceCounter: node.NonceCounter,
+				Fingerprint:  cryptoutils.KeyFingerprint(node.KeyBytes),
+			}
+		}
+		shareable.Fields = append(shareable.Fields, field)
+		manifest.Keys[keyID] = shareable
+
+		manifest.FieldMap[field] = keyID
+		encrypted[field] = encryptedData
+	}
+
+	for _, shareable := range manifest.Keys {
+		sort.Strings(shareable.Fields)
+	}
+	return manifest, encrypted, nil
+}
+
+// FromManifest builds a SecureCV from pieces produced by ExportFields so a
+// recipient can call GetField. Every field must map to a key present in the
+// manifest with valid key material.
+func FromManifest(manifest *models.KeyManifest, encrypted map[string]*models.EncryptedData) (*SecureCV, error) {
+	if manifest == nil {
+		return nil, fmt.Errorf("manifest is nil")
+	}
+
+	scv := NewSecureCV()
+	scoped := &models.KeyManifest{
+		Keys:     manifest.Keys,
+		FieldMap: make(map[string]string, len(encrypted)),
+	}
+	for field, encryptedData := range encrypted {
+		keyID, exists := manifest.FieldMap[field]
+		if !exists {
+			return nil, fmt.Errorf("no key mapping for field '%s'", field)
+		}
+		if _, exists := manifest.Keys[keyID]; !exists {
+			return nil, fmt.Errorf("key %s for field '%s' missing from manifest", keyID, field)
+		}
+		scv.encrypted[field] = encryptedData
+		scv.fieldKeyMap[field] = keyID
+		scoped.FieldMap[field] = keyID
+	}
+
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	if err := scv.importManifest(scoped); err != nil {
+		return nil, err
+	}
+	if err := scv.reconcile(); err != nil {
+		return nil, err
+	}
+	return scv, nil
+}
+
+// ExportKeysPEM writes every active key in the chain as concatenated PEM
+// blocks; revoked and expired keys are skipped
+func (scv *SecureCV) ExportKeysPEM(w io.Writer) error {
+	for _, node := range scv.keys.GetAllKeys() {
+		if node.PastExpiry() {
+			continue
+		}
+		if _, err := io.WriteString(w, cryptoutils.EncodeKeyPEM(node.KeyID, node.KeyBytes)); err != nil {
+			return fmt.Errorf("failed to write key %s: %v", node.KeyID, err)
+		}
+	}
+	return nil
+}
+
+// ExportKeysJWKS writes every active key in the chain as a JWK Set
+// (cryptoutils.ToJWK); revoked and expired keys are skipped
+func (scv *SecureCV) ExportKeysJWKS(w io.Writer) error {
+	set := struct {
+		Keys []json.RawMessage `json:"keys"`
+	}{Keys: []json.RawMessage{}}
+
+	for _, node := range scv.keys.GetAllKeys() {
+		if node.Revoked || node.PastExpiry() {
+			continue
+		}
+		jwk, err := cryptoutils.ToJWK(node.KeyID, node.KeyBytes)
+		if err != nil {
+			return fmt.Errorf("failed to encode key %s: %v", node.KeyID, err)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	data, err := json.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,55 @@
+package securecv
+
+import (
+	"field_cipher/models"
+	"field_cipher/utils/cryptoutils"
+	"fmt"
+	"io"
+)
+
+// LoadFieldFromReader encrypts a large value from r in chunks under the
+// current key. The reader is consumed without holding the CV lock, so slow
+// sources do not block other callers. GetField returns the value as a string.
+func (scv *SecureCV) LoadFieldFromReader(field string, r io.Reader) error {
+	scv.mu.Lock()
+	if err := scv.checkNewField(field); err != nil {
+		scv.mu.Unlock()
+		return err
+	}
+	keyNode, err := scv.currentKey()
+	if err == nil {
+		keyNode, err = scv.usableKey(keyNode)
+	}
+	if err != nil {
+		scv.mu.Unlock()
+		return err
+	}
+	aad := scv.aadFor(field)
+	// Revoking or removing the node wipes its bytes in place, so encrypt
+	// under a copy
+	keyBytes := append([]byte{}, keyNode.KeyBytes...)
+	defer cryptoutils.Zeroize(keyBytes)
+	if scv.maxFieldSize > 0 {
+		r = &limitedReader{r: r, field: field, max: scv.maxFieldSize}
+	}
+	scv.mu.Unlock()
+
+	encryptedData, err := cryptoutils.EncryptStreamData(r, keyBytes, aad)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt field %s: %v", field, err)
+	}
+
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	// Another caller may have added the field while r was being read
+	if err := scv.checkNewField(field); err != nil {
+		return err
+	}
+	// ...or revoked, removed or expired the key it was encrypted under
+	if scv.keys.GetNode(keyNode.KeyID) != keyNode || keyNode.Revoked || keyNode.PastExpiry() {
+		return fmt.Errorf("key %s became unusable while field %s was being read", models.ShortID(keyNode.KeyID, 8), field)
+	}
+	keyNode.UsageCount++
+	return scv.storeField("load", field, encryptedData, keyNode)
+}
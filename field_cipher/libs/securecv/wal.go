@@ -0,0 +1,256 @@
+package securecv
+
+import (
+	"field_cipher/models"
+	"field_cipher/utils/cryptoutils"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// WAL phases
+const (
+	walBegin  = "begin"
+	walCommit = "commit"
+	walAbort  = "abort"
+)
+
+// WALEntry is one record in the write-ahead log
+type WALEntry struct {
+	Seq    uint64      `json:"seq"`
+	Op     string      `json:"op"`
+	Phase  string      `json:"phase"`
+	Field  string      `json:"field,omitempty"`
+	Before *FieldState `json:"before,omitempty"`
+}
+
+// FieldState is a field's before-image: enough to restore it even if its
+// key has since been dropped from the keys file. It contains key material,
+// so the WAL must be protected like a keys file.
+type FieldState struct {
+	KeyID     string                `json:"key_id"`
+	Key       string                `json:"key"`
+	Encrypted *models.EncryptedData `json:"encrypted"`
+}
+
+// WALStore is the storage behind the write-ahead log; *os.File satisfies it
+type WALStore interface {
+	io.ReadWriteCloser
+	Sync() error
+}
+
+// EnableWAL starts logging every mutation to an append-only file at path.
+// Operations that were begun but never committed (e.g. the process crashed
+// mid-rotation) are rolled back to their before-image first.
+func (scv *SecureCV) EnableWAL(path string) error {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	if scv.wal != nil {
+		return fmt.Errorf("WAL already enabled")
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open WAL %s: %v", path, err)
+	}
+	return scv.enableWAL(f)
+}
+
+// EnableWALStore is EnableWAL over an already open store, which DisableWAL
+// closes. Writes are appended after whatever the store already holds.
+func (scv *SecureCV) EnableWALStore(store WALStore) error {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	if scv.wal != nil {
+		return fmt.Errorf("WAL already enabled")
+	}
+	return scv.enableWAL(store)
+}
+
+// enableWAL replays store and starts logging to it; caller holds scv.mu
+func (scv *SecureCV) enableWAL(store WALStore) error {
+	pending, lastSeq, err := readPendingWAL(store)
+	if err != nil {
+		store.Close()
+		return err
+	}
+	scv.wal = store
+	scv.walSeq = lastSeq
+
+	// Undo newest first so overlapping operations unwind in order
+	for i := len(pending) - 1; i >= 0; i-- {
+		if err := scv.restoreFieldState(pending[i].Field, pending[i].Before); err != nil {
+			return fmt.Errorf("failed to roll back WAL entry %d: %v", pending[i].Seq, err)
+		}
+		if err := scv.writeWAL(WALEntry{Seq: pending[i].Seq, Op: pending[i].Op, Phase: walAbort, Field: pending[i].Field}); err != nil {
+			return err
+		}
+	}
+
+	if len(pending) > 0 {
+		scv.dirty.Store(true)
+		scv.logf("Rolled back %d incomplete operations from WAL", len(pending))
+	}
+	return nil
+}
+
+// DisableWAL stops logging and closes the WAL store
+func (scv *SecureCV) DisableWAL() error {
+	scv.mu.Lock()
+	defer scv.mu.Unlock()
+
+	if scv.wal == nil {
+		return nil
+	}
+	err := scv.wal.Close()
+	scv.wal = nil
+	return err
+}
+
+// readPendingWAL returns begun-but-unfinished entries and the last sequence
+func readPendingWAL(r io.Reader) ([]WALEntry, uint64, error) {
+	decoder := json.NewDecoder(r)
+	begun := make(map[uint64]WALEntry)
+	order := make([]uint64, 0)
+	var lastSeq uint64
+
+	for {
+		var entry WALEntry
+		if err := decoder.Decode(&entry); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, 0, fmt.Errorf("corrupt WAL: %v", err)
+		}
+
+		if entry.Seq > lastSeq {
+			lastSeq = entry.Seq
+		}
+		if entry.Phase == walBegin {
+			begun[entry.Seq] = entry
+			order = append(order, entry.Seq)
+		} else {
+			delete(begun, entry.Seq)
+		}
+	}
+
+	pending := make([]WALEntry, 0, len(begun))
+	for _, seq := range order {
+		if entry, exists := begun[seq]; exists {
+			pending = append(pending, entry)
+		}
+	}
+	return pending, lastSeq, nil
+}
+
+// writeWAL appends and syncs one entry; caller holds scv.mu
+func (scv *SecureCV) writeWAL(entry WALEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := scv.wal.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write WAL: %v", err)
+	}
+	return scv.wal.Sync()
+}
+
+// walBeginOp records the intent to mutate field and returns its sequence
+// number; a no-op when the WAL is disabled. Caller holds scv.mu.
+func (scv *SecureCV) walBeginOp(op, field string) (uint64, error) {
+	if scv.wal == nil {
+		return 0, nil
+	}
+
+	scv.walSeq++
+	entry := WALEntry{Seq: scv.walSeq, Op: op, Phase: walBegin, Field: field}
+	if encryptedData, exists := scv.encrypted[field]; exists {
+		keyID := scv.fieldKeyMap[field]
+		state := &FieldState{KeyID: keyID, Encrypted: encryptedData}
+		// A revoked key's bytes are already wiped; recording them would let
+		// recovery reinstate an all-zero key
+		if node := scv.keys.GetNode(keyID); node != nil && !node.Revoked {
+			state.Key = base64.StdEncoding.EncodeToString(node.KeyBytes)
+		}
+		entry.Before = state
+	}
+	return entry.Seq, scv.writeWAL(entry)
+}
+
+// walCommitOp marks an operation complete; caller holds scv.mu
+func (scv *SecureCV) walCommitOp(seq uint64, op, field string) error {
+	if scv.wal == nil {
+		return nil
+	}
+	return scv.writeWAL(WALEntry{Seq: seq, Op: op, Phase: walCommit, Field: field})
+}
+
+// restoreFieldState puts a field back to a before-image; a nil state means
+// the field did not exist. Caller holds scv.mu.
+func (scv *SecureCV) restoreFieldState(field string, state *FieldState) error {
+	if node := scv.keys.GetNode(scv.fieldKeyMap[field]); node != nil {
+		delete(node.EncryptedFields, field)
+	}
+	scv.cache.invalidate(field)
+
+	if state == nil {
+		delete(scv.encrypted, field)
+		delete(scv.fieldKeyMap, field)
+		return nil
+	}
+
+	keyBytes, err := recordedKey(state)
+	if err != nil {
+		return err
+	}
+
+	node := scv.keys.GetNode(state.KeyID)
+	if node != nil && node.Revoked && keyBytes != nil {
+		// The interrupted operation revoked (and wiped) this key; reinstate it
+		node.KeyBytes = keyBytes
+		node.Revoked = false
+	}
+	if node == nil && keyBytes != nil {
+		current := scv.keys.GetCurrentKey()
+		if node, err = scv.keys.ImportKey(state.KeyID, keyBytes); err != nil {
+			return err
+		}
+		// A restored key is historical; keep the chain's current key
+		if current != nil {
+			scv.keys.SetCurrentKey(current.KeyID)
+		}
+	}
+
+	// Without usable key material the field comes back under a revoked or
+	// missing key, and GetField reports it as such
+	scv.encrypted[field] = state.Encrypted
+	scv.fieldKeyMap[field] = state.KeyID
+	if node != nil {
+		node.EncryptedFields[field] = true
+	}
+	return nil
+}
+
+// recordedKey decodes the key material in a before-image, returning nil when
+// none was recorded or the bytes are not a usable key (e.g. wiped to zero)
+func recordedKey(state *FieldState) ([]byte, error) {
+	if state.Key == "" {
+		return nil, nil
+	}
+	keyBytes, err := base64.StdEncoding.DecodeString(state.Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key material for %s: %v", state.KeyID, err)
+	}
+	if cryptoutils.ValidateKey(keyBytes) != nil {
+		return nil, nil
+	}
+	for _, b := range keyBytes {
+		if b != 0 {
+			return keyBytes, nil
+		}
+	}
+	return nil, nil
+}
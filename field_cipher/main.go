@@ -1,21 +1,51 @@
 package main
 
 import (
+    "field_cipher/libs/cli"
     "field_cipher/tests"
+    "field_cipher/utils/cryptoutils"
+    "fmt"
+    "os"
 )
 
 func main() {
-    // Run all test cases
-    tests.RunAllTests()
-    
-    // Or run specific demonstrations
-    tests.DemoSingleKey()
-    tests.DemoMultiKey()
-    tests.DemoKeyRotation()
+    os.Exit(run(os.Args[1:]))
+}
+
+// run executes the command line and returns the process exit code
+func run(args []string) int {
+    opts, err := cli.Parse(args)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+        cli.Usage(os.Stderr)
+        return 2
+    }
+
+    if opts.Demo {
+        // Run all test cases
+        tests.RunAllTests()
+
+        // Or run specific demonstrations
+        tests.DemoSingleKey()
+        tests.DemoMultiKey()
+        tests.DemoKeyRotation()
+        return 0
+    }
+
+    if err := cryptoutils.SelfTest(); err != nil {
+        fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+        return 1
+    }
+
+    if err := cli.Execute(opts, os.Stdout); err != nil {
+        fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+        return 1
+    }
+    return 0
 }
 
 /*
-% go run main.go
+% go run . -demo
 Loaded data from cv_data.json
 Loaded 10 fields from CV data
 
@@ -1,8 +1,12 @@
 package models
 
 import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"time"
 )
 
@@ -12,6 +16,11 @@ type KeyNode struct {
 	KeyBytes         []byte
 	Timestamp        int64
 	Revoked          bool
+	NonceCounter     uint64 // next counter for derived nonces
+	ExpiresAt        int64  // unix nanoseconds after which the key is unusable; 0 never expires
+	RotatedFrom      string // key ID this key replaced during rotation
+	UsageCount       uint64 // encryptions performed under this key
+	MaxUsage         uint64 // encryptions allowed before rotation; 0 is unlimited
 	EncryptedFields  map[string]bool
 	Prev             *KeyNode
 	Next             *KeyNode
@@ -22,31 +31,118 @@ type EncryptedData struct {
 	Nonce      string `json:"nonce"`
 	Ciphertext string `json:"ciphertext"`
 	Type       string `json:"type"`
+	Segments   []*Segment `json:"segments,omitempty"` // set when Type is "partial"
+	Metadata   map[string]string `json:"metadata,omitempty"` // authenticated labels, bound into the AAD
+	Derived    bool `json:"derived,omitempty"` // encrypted under HKDF(key, field name) rather than the key itself
+}
+
+// Segment is one piece of a partially encrypted string: either cleartext or
+// an encrypted region
+type Segment struct {
+	Clear  string         `json:"clear,omitempty"`
+	Sealed *EncryptedData `json:"sealed,omitempty"`
 }
 
 // ShareableKey represents key information for sharing
 type ShareableKey struct {
 	KeyID string   `json:"key_id"`
 	Key   string   `json:"key"`
+	KeyHex string  `json:"key_hex,omitempty"` // same key bytes hex-encoded; either Key or KeyHex is enough on import
 	Fields []string `json:"fields"`
+	NonceCounter uint64 `json:"nonce_counter,omitempty"`
+	Fingerprint  string `json:"fingerprint,omitempty"` // cryptoutils.KeyFingerprint of the key bytes
+	Wrapped      *EncryptedData `json:"wrapped,omitempty"` // key wrapped under a master key; Key is empty when set
+	Timestamp    int64  `json:"timestamp,omitempty"`    // creation time, unix seconds
+	Revoked      bool   `json:"revoked,omitempty"`
+	ExpiresAt    int64  `json:"expires_at,omitempty"`   // unix nanoseconds; 0 never expires
+	RotatedFrom  string `json:"rotated_from,omitempty"`
+	UsageCount   uint64 `json:"usage_count,omitempty"`
+	MaxUsage     uint64 `json:"max_usage,omitempty"`
+}
+
+// DecodeKey returns the key bytes from Key (base64) or KeyHex, failing if
+// both are set and disagree
+func (sk ShareableKey) DecodeKey() ([]byte, error) {
+	var fromBase64, fromHex []byte
+	var err error
+	if sk.Key != "" {
+		if fromBase64, err = base64.StdEncoding.DecodeString(sk.Key); err != nil {
+			return nil, fmt.Errorf("invalid base64 key: %v", err)
+		}
+	}
+	if sk.KeyHex != "" {
+		if fromHex, err = hex.DecodeString(sk.KeyHex); err != nil {
+			return nil, fmt.Errorf("invalid hex key: %v", err)
+		}
+	}
+
+	switch {
+	case sk.Key != "" && sk.KeyHex != "":
+		if subtle.ConstantTimeCompare(fromBase64, fromHex) != 1 {
+			return nil, fmt.Errorf("key and key_hex disagree")
+		}
+		return fromBase64, nil
+	case sk.KeyHex != "":
+		return fromHex, nil
+	}
+	return fromBase64, nil
+}
+
+// FieldBundle is a self-contained share of one field: its ciphertext plus
+// the key needed to decrypt it
+type FieldBundle struct {
+	Field     string         `json:"field"`
+	Encrypted *EncryptedData `json:"encrypted"`
+	Key       *ShareableKey  `json:"key"`
+}
+
+// RecipientShare is one field shared with several recipients: its ciphertext
+// plus the field key wrapped separately under each recipient's KEK
+type RecipientShare struct {
+	Field      string                    `json:"field"`
+	KeyID      string                    `json:"key_id"`
+	Encrypted  *EncryptedData            `json:"encrypted"`
+	Recipients map[string]*EncryptedData `json:"recipients"` // recipient ID -> wrapped key
 }
 
 // KeyManifest represents all keys for full CV access
 type KeyManifest struct {
 	Keys     map[string]ShareableKey `json:"keys"`
 	FieldMap map[string]string       `json:"field_map"`
+	Order    []string                `json:"order,omitempty"`   // key IDs in chain order (KeyChain.ExportFull)
+	Current  string                  `json:"current,omitempty"` // current key ID (KeyChain.ExportFull)
 }
 
 // EncryptedCV represents the complete encrypted CV structure
 type EncryptedCV struct {
 	EncryptedData map[string]*EncryptedData `json:"encrypted_data"` // Changed to pointer
 	FieldKeyMap   map[string]string        `json:"field_key_map"`
+	Classifications map[string]string      `json:"classifications,omitempty"` // field -> level; absent fields are public
 	Metadata      struct {
-		TotalFields int `json:"total_fields"`
-		TotalKeys   int `json:"total_keys"`
+		TotalFields int    `json:"total_fields"`
+		TotalKeys   int    `json:"total_keys"`
+		FieldDigest string `json:"field_digest,omitempty"` // SHA-256 over the sorted field names, checked against keys files on load
 	} `json:"metadata"`
 }
 
+// CVStats summarizes a SecureCV's fields and keys
+type CVStats struct {
+	TotalFields  int    `json:"total_fields"`
+	TotalKeys    int    `json:"total_keys"`
+	ActiveKeys   int    `json:"active_keys"`
+	RevokedKeys  int    `json:"revoked_keys"`
+	CurrentKeyID string `json:"current_key_id"` // empty when the chain has no keys
+}
+
+// CVBundle is a single portable file holding an encrypted CV and, optionally,
+// the keys to decrypt it
+type CVBundle struct {
+	Version   int          `json:"version"`
+	CreatedAt int64        `json:"created_at"` // unix seconds
+	CV        *EncryptedCV `json:"cv"`
+	Keys      *KeyManifest `json:"keys,omitempty"`
+}
+
 // Display prints the key node information
 func (kn *KeyNode) Display(position int, isCurrent bool) {
 	status := "ACTIVE"
@@ -63,7 +159,7 @@ func (kn *KeyNode) Display(position int, isCurrent bool) {
 		fields = append(fields, field)
 	}
 
-	fmt.Printf("%d. %s... - %s%s\n", position, kn.KeyID[:12], status, currentMarker)
+	fmt.Printf("%d. %s... - %s%s\n", position, ShortID(kn.KeyID, 12), status, currentMarker)
 	fmt.Printf("   Fields: %d - %v\n", len(fields), fields[:min(3, len(fields))])
 }
 
@@ -81,6 +177,27 @@ func (ed *EncryptedData) FromJSON(jsonStr string) error {
 	return json.Unmarshal([]byte(jsonStr), ed)
 }
 
+// WriteTo writes the encrypted CV to w as indented JSON, the same form
+// SaveEncryptedCV writes to disk, implementing io.WriterTo
+func (cv *EncryptedCV) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(cv, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal encrypted CV: %v", err)
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// ReadEncryptedCV reads one JSON-encoded encrypted CV from r, as written by
+// WriteTo or SaveEncryptedCV
+func ReadEncryptedCV(r io.Reader) (*EncryptedCV, error) {
+	var cv EncryptedCV
+	if err := json.NewDecoder(r).Decode(&cv); err != nil {
+		return nil, fmt.Errorf("failed to read encrypted CV: %v", err)
+	}
+	return &cv, nil
+}
+
 // GetCreationTime returns the creation time of the key
 func (kn *KeyNode) GetCreationTime() time.Time {
 	return time.Unix(kn.Timestamp, 0)
@@ -91,6 +208,25 @@ func (kn *KeyNode) IsExpired(duration time.Duration) bool {
 	return time.Since(kn.GetCreationTime()) > duration
 }
 
+// UsageExhausted reports whether the key has used up its encryption quota
+func (kn *KeyNode) UsageExhausted() bool {
+	return kn.MaxUsage != 0 && kn.UsageCount >= kn.MaxUsage
+}
+
+// PastExpiry reports whether the key has an expiry time that has passed
+func (kn *KeyNode) PastExpiry() bool {
+	return kn.ExpiresAt != 0 && time.Now().UnixNano() >= kn.ExpiresAt
+}
+
+// ShortID returns the first n characters of s for display, or all of s when
+// it is shorter
+func ShortID(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
 // helper function
 func min(a, b int) int {
 	if a < b {
@@ -1,10 +1,36 @@
 package tests
 
 import (
+	"field_cipher/libs/cli"
+	"field_cipher/libs/keychain"
 	"field_cipher/libs/securecv"
+	"field_cipher/models"
+	"field_cipher/utils/cryptoutils"
 	"field_cipher/utils/fileio"
+	"field_cipher/utils/logging"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"regexp"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -48,6 +74,103 @@ func RunAllTests() {
 	TestMixedDataTypes()
 	TestPerformance()
 	TestKeyRevocation(cvData)
+	TestLoadPair(cvData)
+	TestFieldsByCreationOrder()
+	TestAssociatedData(cvData)
+	TestOriginalJSON()
+	TestKeyChainBackup(cvData)
+	TestIntegrityStatuses(cvData)
+	TestTopology(cvData)
+	TestDerivedNonces(cvData)
+	TestEstimateRotationCost(cvData)
+	TestFieldsBySize()
+	TestWALRecovery(cvData)
+	TestVerifyFieldHash(cvData)
+	TestMinRotationInterval(cvData)
+	TestJWE(cvData)
+	TestFlush(cvData)
+	TestPartialField()
+	TestDiagnoseField(cvData)
+	TestSeededKeyChain(cvData)
+	TestRestore(cvData)
+	TestGenerateRandomHex()
+	TestFieldNameBinding(cvData)
+	TestKeyExpiry(cvData)
+	TestFieldBundle(cvData)
+	TestFieldGroups(cvData)
+	TestRotateAllKeys(cvData)
+	TestKeyHistory(cvData)
+	TestAtomicSave()
+	TestStreamEncryption()
+	TestLoadCVDataValidated(cvData)
+	TestExportFields(cvData)
+	TestFromManifest(cvData)
+	TestLoadModes(cvData)
+	TestParallelLoad(cvData)
+	TestRotateIfOlderThan(cvData)
+	TestKeyPEM(cvData)
+	TestTamperDetection(cvData)
+	TestCLI(cvData)
+	TestAccessStats(cvData)
+	TestKeyFingerprint(cvData)
+	TestKeyZeroize(cvData)
+	TestNonceReuseDetection()
+	TestGetFields(cvData)
+	TestRemoveField(cvData)
+	TestSetField(cvData)
+	TestExplicitKeyIDs()
+	TestWrappedKeys(cvData)
+	TestReKeyMaster(cvData)
+	TestSealedCV(cvData)
+	TestListFilesRecursive()
+	TestListFilesExtension()
+	TestEncryptDirectory(cvData)
+	TestPreviewLoad(cvData)
+	TestKeySize(cvData)
+	TestExportKeysJWKS(cvData)
+	TestShortKeyIDs()
+	TestFindFields()
+	TestValidate(cvData)
+	TestConstantTimeKeyEqual()
+	TestExportFull(cvData)
+	TestImportKeyMetadata(cvData)
+	TestMerge(cvData)
+	TestAutoRotateExpired(cvData)
+	TestFieldMetadata(cvData)
+	TestExportRedacted(cvData)
+	TestForEachField(cvData)
+	TestDecryptCache(cvData)
+	TestNullAndEmptyValues()
+	TestFieldClassification(cvData)
+	TestBundle(cvData)
+	TestDerivedSubkeys(cvData)
+	TestFieldCollisions(cvData)
+	TestLogger(cvData)
+	TestLoadCVContext(cvData)
+	TestMaxFieldSize()
+	TestDiffFields(cvData)
+	TestTypedStats(cvData)
+	TestMalformedCiphertext()
+	TestFieldsForKey(cvData)
+	TestKeyUsageQuota()
+	TestLegacyUntypedRecords()
+	TestRotateAndShare(cvData)
+	TestHexKeys(cvData)
+	TestRandomFailure()
+	TestShareWithRecipients(cvData)
+	TestCanonicalJSON()
+	TestSelfTest()
+	TestKeyChainIterate(cvData)
+	TestCombinedFormat()
+	TestMismatchedKeysFile(cvData)
+	TestUseNumber()
+	TestConvertMode(cvData)
+	TestLoadCVDataFromReader()
+	TestEncryptedCVStreaming(cvData)
+	TestDisplayMasked(cvData)
+	TestRotationGrace(cvData)
+	TestExportCSV(cvData)
+	TestPruneOrphanKeys(cvData)
 
 	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
 	fmt.Println("ALL TESTS COMPLETED SUCCESSFULLY!")
@@ -126,7 +249,7 @@ func TestKeyRotation(cvData map[string]interface{}) {
 		fmt.Printf("❌ Failed to rotate key: %v\n", err)
 		return
 	}
-	fmt.Printf("✅ Key rotated successfully. New key ID: %s...\n", newKeyID[:16])
+	fmt.Printf("✅ Key rotated successfully. New key ID: %s...\n", models.ShortID(newKeyID, 16))
 
 	// Get email after rotation
 	emailAfter, err := cv.GetField("email")
@@ -161,7 +284,7 @@ func TestShareableKeys(cvData map[string]interface{}) {
 	fmt.Printf("✅ Shareable key obtained for 'name':\n")
 	fmt.Printf("   Key ID: %s\n", keyInfo.KeyID)
 	fmt.Printf("   Fields accessible: %v\n", keyInfo.Fields)
-	fmt.Printf("   Key (base64): %s...\n", keyInfo.Key[:20])
+	fmt.Printf("   Key (base64): %s...\n", models.ShortID(keyInfo.Key, 20))
 }
 
 // TestErrorHandling tests error handling for invalid operations
@@ -208,7 +331,7 @@ func TestMultipleRotations(cvData map[string]interface{}) {
 		if err != nil {
 			fmt.Printf("❌ Rotation %d failed: %v\n", i, err)
 		} else {
-			fmt.Printf("✅ Rotation %d successful. Key ID: %s...\n", i, newKeyID[:16])
+			fmt.Printf("✅ Rotation %d successful. Key ID: %s...\n", i, models.ShortID(newKeyID, 16))
 		}
 	}
 
@@ -262,7 +385,7 @@ func TestGetAllKeys(cvData map[string]interface{}) {
 	fmt.Printf("✅ Total fields: %d\n", len(allKeys.FieldMap))
 
 	for keyID, keyInfo := range allKeys.Keys {
-		fmt.Printf("   Key %s... manages %d fields\n", keyID[:12], len(keyInfo.Fields))
+		fmt.Printf("   Key %s... manages %d fields\n", models.ShortID(keyID, 12), len(keyInfo.Fields))
 	}
 }
 
@@ -275,6 +398,7 @@ func TestMixedDataTypes() {
 	mixedData := map[string]interface{}{
 		"string_field":  "Simple string",
 		"number_field":  42,
+		"float_field":   3.5,
 		"boolean_field": true,
 		"array_field":   []interface{}{"item1", "item2", "item3"},
 		"object_field": map[string]interface{}{
@@ -300,6 +424,27 @@ func TestMixedDataTypes() {
 
 	objectVal, _ := cv.GetField("object_field")
 	fmt.Printf("   Object field: %v\n", objectVal)
+
+	numberVal, _ := cv.GetField("number_field")
+	if n, ok := numberVal.(int); ok && n == 42 {
+		fmt.Printf("✅ Number field restored as int: %v\n", numberVal)
+	} else {
+		fmt.Printf("❌ Number field restored as %T: %v\n", numberVal, numberVal)
+	}
+
+	boolVal, _ := cv.GetField("boolean_field")
+	if b, ok := boolVal.(bool); ok && b {
+		fmt.Printf("✅ Boolean field restored as bool: %v\n", boolVal)
+	} else {
+		fmt.Printf("❌ Boolean field restored as %T: %v\n", boolVal, boolVal)
+	}
+
+	floatVal, _ := cv.GetField("float_field")
+	if f, ok := floatVal.(float64); ok && f == 3.5 {
+		fmt.Printf("✅ Float field restored as float64: %v\n", floatVal)
+	} else {
+		fmt.Printf("❌ Float field restored as %T: %v\n", floatVal, floatVal)
+	}
 }
 
 // TestPerformance tests performance with many fields
@@ -333,11 +478,5102 @@ func TestKeyRevocation(cvData map[string]interface{}) {
 	fmt.Println("TEST: KEY REVOCATION")
 	fmt.Printf("%s\n", strings.Repeat("=", 70))
 
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+
+	if err := cv.RevokeFieldKey("email"); err != nil {
+		fmt.Printf("❌ Failed to revoke field key: %v\n", err)
+		return
+	}
+	if _, err := cv.GetField("email"); err != nil && strings.Contains(err.Error(), "field key revoked") {
+		fmt.Printf("✅ Revoked field no longer decrypts: %v\n", err)
+	} else {
+		fmt.Printf("❌ Revoked field still readable or wrong error: %v\n", err)
+	}
+	if _, err := cv.GetField("phone"); err == nil {
+		fmt.Println("✅ Fields under other keys remain readable")
+	} else {
+		fmt.Printf("❌ Unrelated field failed: %v\n", err)
+	}
+	if _, err := cv.GetField("no_such_field"); err != nil && strings.Contains(err.Error(), "not found") {
+		fmt.Println("✅ Missing field reported as not found, not revoked")
+	} else {
+		fmt.Printf("❌ Missing field error: %v\n", err)
+	}
+
+	// A shared key backs every field in single mode
+	shared := securecv.NewSecureCV()
+	shared.LoadCV(cvData, "single")
+	if err := shared.RevokeKey(shared.KeyChain().GetCurrentKey().KeyID); err != nil {
+		fmt.Printf("❌ Failed to revoke shared key: %v\n", err)
+		return
+	}
+	readable := 0
+	for field := range cvData {
+		if _, err := shared.GetField(field); err == nil {
+			readable++
+		}
+	}
+	if readable == 0 {
+		fmt.Println("✅ Revoking a shared key locks every field it backs")
+	} else {
+		fmt.Printf("❌ %d fields still readable after shared key revocation\n", readable)
+	}
+}
+
+// TestLoadPair tests loading and reconciling a saved CV with its keys file
+func TestLoadPair(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: LOAD PAIR")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	cvFile := filepath.Join(dir, "cv.json")
+	keysFile := filepath.Join(dir, "keys.json")
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	cv.SaveEncryptedCV(cvFile)
+	cv.SaveKeys(keysFile)
+
+	// Valid pair
+	loaded, err := securecv.LoadPair(cvFile, keysFile)
+	if err != nil {
+		fmt.Printf("❌ Failed to load valid pair: %v\n", err)
+		return
+	}
+	email, err := loaded.GetField("email")
+	if err != nil || email != cvData["email"] {
+		fmt.Printf("❌ Loaded pair did not decrypt email: %v, %v\n", email, err)
+	} else {
+		fmt.Println("✅ Valid pair loaded and decrypted")
+	}
+
+	// Pair with a missing key
+	var manifest models.KeyManifest
+	fileio.LoadJSON(keysFile, &manifest)
+	delete(manifest.Keys, manifest.FieldMap["email"])
+	missingFile := filepath.Join(dir, "keys_missing.json")
+	fileio.SaveJSON(missingFile, &manifest)
+
+	_, err = securecv.LoadPair(cvFile, missingFile)
+	if err != nil && strings.Contains(err.Error(), "'email'") {
+		fmt.Printf("✅ Missing key detected: %v\n", err)
+	} else {
+		fmt.Printf("❌ Expected missing key error for email, got: %v\n", err)
+	}
+
+	// Pair with a wrong key
+	single := securecv.NewSecureCV()
+	single.LoadCV(cvData, "single")
+	single.SaveEncryptedCV(cvFile)
+	single.SaveKeys(keysFile)
+
+	manifest = models.KeyManifest{}
+	fileio.LoadJSON(keysFile, &manifest)
+	for keyID, key := range manifest.Keys {
+		key.Key = base64.StdEncoding.EncodeToString(cryptoutils.GenerateRandomBytes(32))
+		key.KeyHex = ""
+		key.Fingerprint = "" // exercise the decryption sanity check, not the fingerprint
+		manifest.Keys[keyID] = key
+	}
+	wrongFile := filepath.Join(dir, "keys_wrong.json")
+	fileio.SaveJSON(wrongFile, &manifest)
+
+	_, err = securecv.LoadPair(cvFile, wrongFile)
+	if err != nil && strings.Contains(err.Error(), "sanity check failed") {
+		fmt.Printf("✅ Wrong key detected: %v\n", err)
+	} else {
+		fmt.Printf("❌ Expected sanity check failure, got: %v\n", err)
+	}
+}
+
+// TestFieldsByCreationOrder tests ordering fields by key creation time
+func TestFieldsByCreationOrder() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: FIELDS BY CREATION ORDER")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(map[string]interface{}{
+		"name":   "Violet K.",
+		"email":  "Violet.tech@Violet.com",
+		"phone":  "C: (347)-555-1294",
+		"skills": "Go, Rust",
+	}, "multi")
+
+	// Fake staggered creation times; phone and email share a timestamp
+	base := time.Now().Unix()
+	staggered := map[string]int64{
+		"skills": base - 30,
+		"phone":  base - 20,
+		"email":  base - 20,
+		"name":   base - 10,
+	}
+	for field, ts := range staggered {
+		keyInfo, _ := cv.GetShareableKey(field)
+		cv.KeyChain().GetNode(keyInfo.KeyID).Timestamp = ts
+	}
+
+	expected := []string{"skills", "email", "phone", "name"}
+	order := cv.FieldsByCreationOrder()
+	if strings.Join(order, ",") == strings.Join(expected, ",") {
+		fmt.Printf("✅ Fields ordered by creation time: %v\n", order)
+	} else {
+		fmt.Printf("❌ Expected order %v, got %v\n", expected, order)
+	}
+}
+
+// TestAssociatedData tests binding caller-supplied AAD into ciphertexts
+func TestAssociatedData(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: ASSOCIATED DATA")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.SetAAD([]byte("tenant-a"))
+	cv.LoadCV(cvData, "single")
+
+	if _, err := cv.GetField("email"); err != nil {
+		fmt.Printf("❌ Failed to decrypt with matching AAD: %v\n", err)
+	} else {
+		fmt.Println("✅ Field decrypts with matching AAD")
+	}
+
+	cv.SetAAD([]byte("tenant-b"))
+	if _, err := cv.GetField("email"); err != nil {
+		fmt.Printf("✅ Mismatched AAD rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Field decrypted under a different AAD")
+	}
+	cv.SetAAD([]byte("tenant-a"))
+
+	// Per-field override only affects that field
+	cv.SetFieldAAD("email", []byte("version-2"))
+	_, emailErr := cv.GetField("email")
+	_, nameErr := cv.GetField("name")
+	if emailErr != nil && nameErr == nil {
+		fmt.Println("✅ Per-field AAD override applied to email only")
+	} else {
+		fmt.Printf("❌ Unexpected override behavior: email=%v, name=%v\n", emailErr, nameErr)
+	}
+
+	// Same check directly against the crypto primitives
+	key := cryptoutils.GenerateRandomBytes(32)
+	encrypted, _ := cryptoutils.EncryptData("secret", key, []byte("context-1"))
+	if _, err := cryptoutils.DecryptData(encrypted, key, []byte("context-2")); err != nil {
+		fmt.Println("✅ DecryptData rejects mismatched AAD")
+	} else {
+		fmt.Println("❌ DecryptData accepted mismatched AAD")
+	}
+}
+
+// TestOriginalJSON tests reconstructing the plaintext CV JSON
+func TestOriginalJSON() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: ORIGINAL JSON")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	original, err := fileio.LoadCVData("cv_data.json")
+	if err != nil {
+		fmt.Printf("❌ Failed to load cv_data.json: %v\n", err)
+		return
+	}
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(original, "multi")
+
+	data, err := cv.OriginalJSON()
+	if err != nil {
+		fmt.Printf("❌ Failed to build original JSON: %v\n", err)
+		return
+	}
+
+	var roundTrip map[string]interface{}
+	if err := json.Unmarshal(data, &roundTrip); err != nil {
+		fmt.Printf("❌ Original JSON does not parse: %v\n", err)
+	} else if reflect.DeepEqual(roundTrip, original) {
+		fmt.Println("✅ Original JSON matches cv_data.json")
+	} else {
+		fmt.Println("❌ Original JSON differs from cv_data.json")
+	}
+}
+
+// TestKeyChainBackup tests passphrase-encrypted key chain backup and restore
+func TestKeyChainBackup(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: KEY CHAIN BACKUP")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	cvFile := filepath.Join(dir, "cv.json")
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	cv.SaveEncryptedCV(cvFile)
+
+	backup, err := cv.KeyChain().Backup("correct horse battery staple")
+	if err != nil {
+		fmt.Printf("❌ Failed to back up key chain: %v\n", err)
+		return
+	}
+	manifest := cv.GetAllKeys()
+	if strings.Contains(string(backup), manifest.Keys[manifest.FieldMap["email"]].Key) {
+		fmt.Println("❌ Backup contains plaintext key material")
+	}
+
+	restored, err := keychain.RestoreKeyChain(backup, "correct horse battery staple")
+	if err != nil {
+		fmt.Printf("❌ Failed to restore key chain: %v\n", err)
+		return
+	}
+	fmt.Printf("✅ Restored %d keys from backup\n", restored.Size())
+
+	restoredCV := securecv.NewSecureCVWithKeyChain(restored)
+	restoredCV.LoadEncryptedCV(cvFile)
+	failures := 0
+	for field, value := range cvData {
+		decrypted, err := restoredCV.GetField(field)
+		if err != nil || decrypted != value {
+			failures++
+		}
+	}
+	if failures == 0 {
+		fmt.Println("✅ All fields decrypt with the restored key chain")
+	} else {
+		fmt.Printf("❌ %d fields failed to decrypt with the restored key chain\n", failures)
+	}
+
+	if _, err := keychain.RestoreKeyChain(backup, "wrong passphrase"); err != nil {
+		fmt.Printf("✅ Wrong passphrase rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Restore succeeded with the wrong passphrase")
+	}
+}
+
+// flipCiphertextByte corrupts one byte of a field's ciphertext in a saved CV file
+func flipCiphertextByte(cvFile, field string) error {
+	var data models.EncryptedCV
+	if err := fileio.LoadJSON(cvFile, &data); err != nil {
+		return err
+	}
+	raw, err := base64.StdEncoding.DecodeString(data.EncryptedData[field].Ciphertext)
+	if err != nil {
+		return err
+	}
+	raw[0] ^= 0xff
+	data.EncryptedData[field].Ciphertext = base64.StdEncoding.EncodeToString(raw)
+	return fileio.SaveJSON(cvFile, &data)
+}
+
+// TestIntegrityStatuses tests that integrity checks tell revocation from tampering
+func TestIntegrityStatuses(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: INTEGRITY STATUSES")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	cvFile := filepath.Join(dir, "cv.json")
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	phoneKey, _ := cv.GetShareableKey("phone")
+	cv.KeyChain().RevokeKey(phoneKey.KeyID)
+
+	cv.SaveEncryptedCV(cvFile)
+	if err := flipCiphertextByte(cvFile, "email"); err != nil {
+		fmt.Printf("❌ Failed to corrupt email: %v\n", err)
+		return
+	}
+
+	checked := securecv.NewSecureCVWithKeyChain(cv.KeyChain())
+	checked.LoadEncryptedCV(cvFile)
+	results, err := checked.VerifyIntegrity()
+	if err != nil {
+		fmt.Printf("   Summary: %v\n", err)
+	}
+
+	expected := map[string]securecv.FieldStatus{
+		"phone": securecv.FieldRevoked,
+		"email": securecv.FieldCorrupted,
+		"name":  securecv.FieldOK,
+	}
+	for field, want := range expected {
+		if got := securecv.StatusOf(results[field]); got == want {
+			fmt.Printf("✅ Field '%s' reported as %s\n", field, got)
+		} else {
+			fmt.Printf("❌ Field '%s' reported as %s, expected %s\n", field, got, want)
+		}
+	}
+}
+
+// TestTopology tests exporting the key structure without key material
+func TestTopology(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: TOPOLOGY")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	single := securecv.NewSecureCV()
+	single.LoadCV(cvData, "single")
+	topology := single.Topology()
+	if len(topology.Groups) == 1 && len(topology.Fields) == len(cvData) {
+		for keyID, fields := range topology.Groups {
+			if len(fields) == len(cvData) {
+				fmt.Printf("✅ Single mode: all %d fields grouped under %s...\n", len(fields), models.ShortID(keyID, 8))
+			} else {
+				fmt.Printf("❌ Single mode group has %d fields, expected %d\n", len(fields), len(cvData))
+			}
+		}
+	} else {
+		fmt.Printf("❌ Single mode topology has %d groups\n", len(topology.Groups))
+	}
+
+	multi := securecv.NewSecureCV()
+	multi.LoadCV(cvData, "multi")
+	topology = multi.Topology()
+	correct := len(topology.Groups) == len(cvData)
+	for field, keyID := range topology.Fields {
+		group := topology.Groups[keyID]
+		if len(group) != 1 || group[0] != field {
+			correct = false
+		}
+	}
+	if correct {
+		fmt.Printf("✅ Multi mode: %d fields in %d separate groups\n", len(topology.Fields), len(topology.Groups))
+	} else {
+		fmt.Println("❌ Multi mode topology does not give each field its own group")
+	}
+
+	encoded, _ := json.Marshal(topology)
+	manifest := multi.GetAllKeys()
+	if strings.Contains(string(encoded), manifest.Keys[manifest.FieldMap["email"]].Key) {
+		fmt.Println("❌ Topology leaks key material")
+	} else {
+		fmt.Println("✅ Topology contains no key material")
+	}
+}
+
+// TestDerivedNonces tests that HKDF-derived nonces never repeat across
+// instances sharing a key and its persisted counter
+func TestDerivedNonces(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: DERIVED NONCES")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	cvFile := filepath.Join(dir, "cv.json")
+	keysFile := filepath.Join(dir, "keys.json")
+
+	// First instance encrypts under a single key
+	first := securecv.NewSecureCV()
+	first.SetNonceMode(securecv.NonceDerived)
+	first.LoadCV(cvData, "single")
+	first.SaveEncryptedCV(cvFile)
+	first.SaveKeys(keysFile)
+
+	// Second instance reimports the same key and counter and keeps encrypting
+	second, err := securecv.LoadPair(cvFile, keysFile)
+	if err != nil {
+		fmt.Printf("❌ Failed to load pair: %v\n", err)
+		return
+	}
+	second.SetNonceMode(securecv.NonceDerived)
+	extra := make(map[string]interface{})
+	for i := 0; i < 5; i++ {
+		extra[fmt.Sprintf("extra_%d", i)] = fmt.Sprintf("extra value %d", i)
+	}
+	second.LoadCV(extra, "single")
+	second.SaveEncryptedCV(cvFile)
+
+	var saved models.EncryptedCV
+	fileio.LoadJSON(cvFile, &saved)
+	seen := make(map[string]string)
+	repeats := 0
+	for field, data := range saved.EncryptedData {
+		if other, exists := seen[data.Nonce]; exists {
+			fmt.Printf("❌ Nonce reused by '%s' and '%s'\n", field, other)
+			repeats++
+		}
+		seen[data.Nonce] = field
+	}
+	if repeats == 0 && len(saved.EncryptedData) == len(cvData)+len(extra) {
+		fmt.Printf("✅ %d nonces across two instances, none repeated\n", len(seen))
+	} else if repeats == 0 {
+		fmt.Printf("❌ Expected %d fields, found %d\n", len(cvData)+len(extra), len(saved.EncryptedData))
+	}
+
+	key := cryptoutils.GenerateRandomBytes(32)
+	a, _ := cryptoutils.DeriveNonce(key, 7)
+	b, _ := cryptoutils.DeriveNonce(key, 7)
+	c, _ := cryptoutils.DeriveNonce(key, 8)
+	if string(a) == string(b) && string(a) != string(c) {
+		fmt.Println("✅ DeriveNonce is deterministic per counter")
+	} else {
+		fmt.Println("❌ DeriveNonce is not deterministic per counter")
+	}
+}
+
+// TestEstimateRotationCost tests rotation cost estimates against real sizes
+func TestEstimateRotationCost(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: ESTIMATE ROTATION COST")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
 	cv := securecv.NewSecureCV()
 	cv.LoadCV(cvData, "single")
 
-	// Get a key ID to revoke (this would need to be implemented in keychain)
-	fmt.Println("ℹ️  Key revocation test - would need keychain revocation implementation")
+	fields := []string{"nonexistent_field"}
+	actualBytes := 0
+	for field := range cvData {
+		fields = append(fields, field)
+		value, _ := cv.GetField(field)
+		actualBytes += len(value.(string))
+	}
+
+	estimate := cv.EstimateRotationCost(fields)
+	if estimate.PlaintextBytes == actualBytes {
+		fmt.Printf("✅ Estimated %d plaintext bytes, matches actual\n", estimate.PlaintextBytes)
+	} else {
+		fmt.Printf("❌ Estimated %d plaintext bytes, actual %d\n", estimate.PlaintextBytes, actualBytes)
+	}
+	if estimate.KeysMinted == len(cvData) && len(estimate.Missing) == 1 {
+		fmt.Printf("✅ %d keys to mint, missing fields: %v\n", estimate.KeysMinted, estimate.Missing)
+	} else {
+		fmt.Printf("❌ Unexpected estimate: %+v\n", estimate)
+	}
+	fmt.Printf("   Estimated time: %v\n", estimate.EstimatedTime)
+}
+
+// TestFieldsBySize tests listing fields by ciphertext size
+func TestFieldsBySize() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: FIELDS BY SIZE")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(map[string]interface{}{
+		"tiny":   "ab",
+		"medium": strings.Repeat("m", 100),
+		"large":  strings.Repeat("l", 5000),
+		"small":  strings.Repeat("s", 10),
+	}, "multi")
+
+	// GCM adds a 16-byte tag to each plaintext
+	expected := []securecv.FieldSize{
+		{Field: "large", Size: 5016},
+		{Field: "medium", Size: 116},
+		{Field: "small", Size: 26},
+		{Field: "tiny", Size: 18},
+	}
+	sizes := cv.FieldsBySize()
+	if reflect.DeepEqual(sizes, expected) {
+		fmt.Printf("✅ Fields sorted by size: %v\n", sizes)
+	} else {
+		fmt.Printf("❌ Expected %v, got %v\n", expected, sizes)
+	}
+}
+
+// TestWALRecovery tests rolling back an operation interrupted before commit
+func TestWALRecovery(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: WAL RECOVERY")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	cvFile := filepath.Join(dir, "cv.json")
+	keysFile := filepath.Join(dir, "keys.json")
+	walFile := filepath.Join(dir, "cv.wal")
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	if err := cv.EnableWAL(walFile); err != nil {
+		fmt.Printf("❌ Failed to enable WAL: %v\n", err)
+		return
+	}
+	original, _ := cv.GetShareableKey("email")
+	cv.RotateFieldKey("email")
+	cv.SaveEncryptedCV(cvFile)
+	cv.SaveKeys(keysFile)
+	cv.DisableWAL()
+
+	// Simulate a crash after the begin record: drop the trailing commit
+	wal, _ := os.ReadFile(walFile)
+	lines := strings.Split(strings.TrimSpace(string(wal)), "\n")
+	os.WriteFile(walFile, []byte(strings.Join(lines[:len(lines)-1], "\n")+"\n"), 0600)
+
+	recovered, err := securecv.LoadPair(cvFile, keysFile)
+	if err != nil {
+		fmt.Printf("❌ Failed to load pair: %v\n", err)
+		return
+	}
+	if err := recovered.EnableWAL(walFile); err != nil {
+		fmt.Printf("❌ Recovery failed: %v\n", err)
+		return
+	}
+	defer recovered.DisableWAL()
+
+	keyInfo, _ := recovered.GetShareableKey("email")
+	email, err := recovered.GetField("email")
+	if keyInfo.KeyID == original.KeyID && err == nil && email == cvData["email"] {
+		fmt.Println("✅ Interrupted rotation rolled back to the original key")
+	} else {
+		fmt.Printf("❌ Unexpected state after recovery: key %s, value %v, err %v\n", keyInfo.KeyID, email, err)
+	}
+	if _, err := recovered.VerifyIntegrity(); err == nil {
+		fmt.Println("✅ CV is consistent after recovery")
+	} else {
+		fmt.Printf("❌ CV inconsistent after recovery: %v\n", err)
+	}
+
+	wal, _ = os.ReadFile(walFile)
+	lines = strings.Split(strings.TrimSpace(string(wal)), "\n")
+	var last securecv.WALEntry
+	json.Unmarshal([]byte(lines[len(lines)-1]), &last)
+	if last.Phase == "abort" && last.Field == "email" {
+		fmt.Println("✅ Rolled back operation recorded as aborted")
+	} else {
+		fmt.Printf("❌ Expected abort record, got %+v\n", last)
+	}
+
+	// An op begun on a field whose key is revoked must not carry the wiped key
+	revoked := securecv.NewSecureCV()
+	revoked.LoadCV(cvData, "multi")
+	revoked.SetAllowOverwrite(true)
+	emailKey := revoked.GetAllKeys().FieldMap["email"]
+	revoked.RevokeKey(emailKey)
+	store := &failingWAL{remaining: 1}
+	revoked.EnableWALStore(store)
+	revoked.SetField("email", "new@example.com", "multi")
+	revoked.DisableWAL()
+
+	var begun securecv.WALEntry
+	json.Unmarshal(bytes.TrimSpace(store.Bytes()), &begun)
+	if begun.Before != nil && begun.Before.KeyID == emailKey && begun.Before.Key == "" {
+		fmt.Println("✅ Before-image omits the bytes of a revoked key")
+	} else {
+		fmt.Printf("❌ Unexpected before-image: %+v\n", begun.Before)
+	}
+
+	// A WAL written before that fix holds the zeroed bytes; replay keeps the key revoked
+	begun.Before.Key = base64.StdEncoding.EncodeToString(make([]byte, 32))
+	line, _ := json.Marshal(begun)
+	replay := &failingWAL{remaining: 1}
+	replay.Buffer.Write(append(line, '\n'))
+	if err := revoked.EnableWALStore(replay); err != nil {
+		fmt.Printf("❌ Replay failed: %v\n", err)
+		return
+	}
+	revoked.DisableWAL()
+	node := revoked.KeyChain().GetNode(emailKey)
+	if _, err := revoked.GetField("email"); err != nil && node != nil && node.Revoked {
+		fmt.Printf("✅ Replaying a zeroed key leaves it revoked: %v\n", err)
+	} else {
+		fmt.Printf("❌ Zeroed key reinstated by replay: %v\n", err)
+	}
+}
+
+// TestVerifyFieldHash tests verifying decrypted fields against published hashes
+func TestVerifyFieldHash(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: VERIFY FIELD HASH")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+
+	expected := sha256.Sum256([]byte(cvData["email"].(string)))
+	if ok, err := cv.VerifyFieldHash("email", expected[:]); ok && err == nil {
+		fmt.Println("✅ Matching hash verified")
+	} else {
+		fmt.Printf("❌ Matching hash not verified: %v, %v\n", ok, err)
+	}
+
+	wrong := sha256.Sum256([]byte("someone.else@example.com"))
+	if ok, err := cv.VerifyFieldHash("email", wrong[:]); !ok && err == nil {
+		fmt.Println("✅ Mismatching hash rejected")
+	} else {
+		fmt.Printf("❌ Mismatching hash accepted: %v, %v\n", ok, err)
+	}
+
+	if _, err := cv.VerifyFieldHash("nonexistent_field", expected[:]); err != nil {
+		fmt.Printf("✅ Missing field reported: %v\n", err)
+	} else {
+		fmt.Println("❌ Missing field did not return an error")
+	}
+
+	if count := cv.GetAccessStats()["email"].Count; count == 0 {
+		fmt.Println("✅ Verifying hashes does not count as field access")
+	} else {
+		fmt.Printf("❌ Verification recorded %d accesses\n", count)
+	}
+}
+
+// TestMinRotationInterval tests rejecting rotations that come too quickly
+func TestMinRotationInterval(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: MIN ROTATION INTERVAL")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cv := securecv.NewSecureCV()
+	cv.SetClock(func() time.Time { return now })
+	cv.SetMinRotationInterval(time.Hour)
+	cv.LoadCV(cvData, "single")
+
+	if _, err := cv.RotateFieldKey("email"); err != nil {
+		fmt.Printf("❌ First rotation rejected: %v\n", err)
+	} else {
+		fmt.Println("✅ First rotation allowed")
+	}
+
+	now = now.Add(10 * time.Minute)
+	if _, err := cv.RotateFieldKey("email"); err != nil && strings.Contains(err.Error(), "too recently") {
+		fmt.Printf("✅ Rapid rotation rejected: %v\n", err)
+	} else {
+		fmt.Printf("❌ Expected rotated too recently error, got: %v\n", err)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := cv.RotateFieldKey("email"); err != nil {
+		fmt.Printf("❌ Rotation after interval rejected: %v\n", err)
+	} else {
+		fmt.Println("✅ Rotation after interval allowed")
+	}
+
+	// A per-field override replaces the global interval
+	cv.SetFieldMinRotationInterval("phone", 0)
+	cv.RotateFieldKey("phone")
+	if _, err := cv.RotateFieldKey("phone"); err != nil {
+		fmt.Printf("❌ Per-field override ignored: %v\n", err)
+	} else {
+		fmt.Println("✅ Per-field override allows immediate rotation")
+	}
+}
+
+// TestJWE tests exporting fields as compact JWE and decrypting them
+func TestJWE(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: JWE EXPORT")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	recipient, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		fmt.Printf("❌ Failed to generate RSA key: %v\n", err)
+		return
+	}
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	cv.LoadCV(map[string]interface{}{
+		"object_field": map[string]interface{}{"nested": "value"},
+	}, "multi")
+
+	token, err := cv.ExportJWE("email", &recipient.PublicKey)
+	if err != nil {
+		fmt.Printf("❌ Failed to export JWE: %v\n", err)
+		return
+	}
+	parts := strings.Split(token, ".")
+	header, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	if len(parts) == 5 && strings.Contains(string(header), `"alg":"RSA-OAEP"`) && strings.Contains(string(header), `"enc":"A256GCM"`) {
+		fmt.Printf("✅ Compact JWE produced with header %s\n", header)
+	} else {
+		fmt.Printf("❌ Malformed JWE: %d parts, header %s\n", len(parts), header)
+	}
+
+	value, err := securecv.ImportJWE(token, recipient)
+	if err == nil && value == cvData["email"] {
+		fmt.Println("✅ JWE decrypts to the original email")
+	} else {
+		fmt.Printf("❌ JWE decrypted to %v, %v\n", value, err)
+	}
+
+	token, _ = cv.ExportJWE("object_field", &recipient.PublicKey)
+	value, err = securecv.ImportJWE(token, recipient)
+	if obj, ok := value.(map[string]interface{}); ok && err == nil && obj["nested"] == "value" {
+		fmt.Println("✅ JSON field survives the JWE round trip")
+	} else {
+		fmt.Printf("❌ JSON field decrypted to %v, %v\n", value, err)
+	}
+
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	if _, err := securecv.ImportJWE(token, other); err != nil {
+		fmt.Println("✅ JWE rejected by the wrong recipient key")
+	} else {
+		fmt.Println("❌ JWE decrypted with the wrong recipient key")
+	}
+}
+
+// TestFlush tests persisting pending autosave changes on Flush
+func TestFlush(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: FLUSH")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	cvFile := filepath.Join(dir, "cv.json")
+	keysFile := filepath.Join(dir, "keys.json")
+
+	cv := securecv.NewSecureCV()
+	cv.SetAutosave(cvFile, keysFile)
+	cv.LoadCV(cvData, "single")
+	cv.RotateFieldKey("email")
+
+	if fileio.FileExists(cvFile) || !cv.HasPendingChanges() {
+		fmt.Println("❌ Changes were written before Flush")
+	}
+
+	// Flush while readers are active
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cv.GetField("email")
+		}()
+	}
+	err = cv.Flush()
+	wg.Wait()
+	if err != nil {
+		fmt.Printf("❌ Flush failed: %v\n", err)
+		return
+	}
+
+	loaded, err := securecv.LoadPair(cvFile, keysFile)
+	if err != nil {
+		fmt.Printf("❌ Flushed files do not load: %v\n", err)
+		return
+	}
+	email, _ := loaded.GetField("email")
+	if email == cvData["email"] && !cv.HasPendingChanges() {
+		fmt.Println("✅ Pending changes written by Flush")
+	} else {
+		fmt.Printf("❌ Flushed state incorrect: email %v\n", email)
+	}
+
+	// Nothing pending: a second Flush must not touch the files
+	os.Remove(cvFile)
+	if err := cv.Flush(); err == nil && !fileio.FileExists(cvFile) {
+		fmt.Println("✅ Second Flush is a no-op")
+	} else {
+		fmt.Printf("❌ Second Flush rewrote files or failed: %v\n", err)
+	}
+}
+
+// TestPartialField tests encrypting only a region of a string field
+func TestPartialField() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: PARTIAL FIELD")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	cvFile := filepath.Join(dir, "cv.json")
+
+	phone := "C: (347)-555-1294"
+	cv := securecv.NewSecureCV()
+	if err := cv.LoadPartialField("phone", phone, regexp.MustCompile(`\(\d{3}\)`)); err != nil {
+		fmt.Printf("❌ Failed to load partial field: %v\n", err)
+		return
+	}
+
+	value, err := cv.GetField("phone")
+	if err == nil && value == phone {
+		fmt.Printf("✅ Partial field reassembled: %v\n", value)
+	} else {
+		fmt.Printf("❌ Partial field decrypted to %v, %v\n", value, err)
+	}
+
+	cv.SaveEncryptedCV(cvFile)
+	saved, _ := os.ReadFile(cvFile)
+	if strings.Contains(string(saved), "-555-1294") && !strings.Contains(string(saved), "(347)") {
+		fmt.Println("✅ Saved file shows the public part and hides the area code")
+	} else {
+		fmt.Println("❌ Saved file does not have the expected cleartext/encrypted split")
+	}
+
+	// Editing the cleartext part must break authentication
+	var data models.EncryptedCV
+	fileio.LoadJSON(cvFile, &data)
+	for _, segment := range data.EncryptedData["phone"].Segments {
+		if segment.Sealed == nil {
+			segment.Clear = strings.Replace(segment.Clear, "555", "666", 1)
+		}
+	}
+	fileio.SaveJSON(cvFile, &data)
+	tampered := securecv.NewSecureCVWithKeyChain(cv.KeyChain())
+	tampered.LoadEncryptedCV(cvFile)
+	if _, err := tampered.GetField("phone"); err != nil {
+		fmt.Println("✅ Tampered cleartext part rejected")
+	} else {
+		fmt.Println("❌ Tampered cleartext part accepted")
+	}
+}
+
+// TestDiagnoseField tests that each decryption failure is named correctly
+func TestDiagnoseField(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: DIAGNOSE FIELD")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	cvFile := filepath.Join(dir, "cv.json")
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	skillsKey, _ := cv.GetShareableKey("skills")
+	cv.KeyChain().RevokeKey(skillsKey.KeyID)
+	cv.SaveEncryptedCV(cvFile)
+
+	// Break one field per failure mode in the saved file
+	flipCiphertextByte(cvFile, "name")
+	var data models.EncryptedCV
+	fileio.LoadJSON(cvFile, &data)
+	delete(data.FieldKeyMap, "email")
+	data.EncryptedData["phone"].Ciphertext = "!!!not-base64!!!"
+	fileio.SaveJSON(cvFile, &data)
+
+	broken := securecv.NewSecureCVWithKeyChain(cv.KeyChain())
+	broken.LoadEncryptedCV(cvFile)
+	keyless := securecv.NewSecureCV()
+	keyless.LoadEncryptedCV(cvFile)
+
+	cases := []struct {
+		cv    *securecv.SecureCV
+		field string
+		cause string
+	}{
+		{broken, "nonexistent_field", "field missing"},
+		{broken, "email", "no key mapping"},
+		{keyless, "linkedin", "key not in chain"},
+		{broken, "skills", "key revoked"},
+		{broken, "phone", "ciphertext malformed"},
+		{broken, "name", "authentication failed"},
+		{broken, "linkedin", "ok"},
+	}
+	for _, c := range cases {
+		diagnosis := c.cv.DiagnoseField(c.field)
+		if strings.HasPrefix(diagnosis, c.cause+":") {
+			fmt.Printf("✅ %s\n", diagnosis)
+		} else {
+			fmt.Printf("❌ Expected '%s' for %s, got: %s\n", c.cause, c.field, diagnosis)
+		}
+	}
+}
+
+// TestSeededKeyChain tests deterministic per-field keys from a master seed
+func TestSeededKeyChain(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: SEEDED KEY CHAIN")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	cvFile := filepath.Join(dir, "cv.json")
+
+	master := []byte("shared master seed for sync tests")
+	first := keychain.NewKeyChainSeeded(master)
+	second := keychain.NewKeyChainSeeded(master)
+
+	identical := true
+	for field := range cvData {
+		a, b := first.CreateKeyForField(field), second.CreateKeyForField(field)
+		if a.KeyID != b.KeyID || string(a.KeyBytes) != string(b.KeyBytes) {
+			identical = false
+		}
+	}
+	emailKey := first.CreateKeyForField("email")
+	phoneKey := first.CreateKeyForField("phone")
+	otherKey := keychain.NewKeyChainSeeded([]byte("different seed")).CreateKeyForField("email")
+	if identical && string(emailKey.KeyBytes) != string(phoneKey.KeyBytes) && string(emailKey.KeyBytes) != string(otherKey.KeyBytes) {
+		fmt.Println("✅ Same seed gives identical per-field keys; fields and seeds stay distinct")
+	} else {
+		fmt.Println("❌ Seeded key derivation is not deterministic and distinct")
+	}
+
+	// One party encrypts; the other derives the keys independently
+	sender := securecv.NewSecureCVWithKeyChain(keychain.NewKeyChainSeeded(master))
+	sender.LoadCV(cvData, "multi")
+	sender.SaveEncryptedCV(cvFile)
+
+	receiverKeys := keychain.NewKeyChainSeeded(master)
+	for field := range cvData {
+		receiverKeys.CreateKeyForField(field)
+	}
+	receiver := securecv.NewSecureCVWithKeyChain(receiverKeys)
+	receiver.LoadEncryptedCV(cvFile)
+
+	failures := 0
+	for field, value := range cvData {
+		if decrypted, err := receiver.GetField(field); err != nil || decrypted != value {
+			failures++
+		}
+	}
+	if failures == 0 {
+		fmt.Println("✅ CV encrypted from one seeded chain decrypts with the other")
+	} else {
+		fmt.Printf("❌ %d fields failed to cross-decrypt\n", failures)
+	}
+
+	// A revoked derived key has been wiped and must not be handed out again
+	sender.SetAllowOverwrite(true)
+	sender.RevokeKey(sender.GetAllKeys().FieldMap["email"])
+	if err := sender.SetField("email", "new@example.com", "multi"); err != nil && strings.Contains(err.Error(), "revoked") {
+		fmt.Printf("✅ Setting a field whose derived key is revoked fails: %v\n", err)
+	} else {
+		fmt.Printf("❌ Field re-set under a revoked derived key: %v\n", err)
+	}
+}
+
+// TestRestore tests reloading a CV and its keys into a fresh instance
+func TestRestore(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: RESTORE")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	cvFile := filepath.Join(dir, "cv.json")
+	keysFile := filepath.Join(dir, "keys.json")
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	cv.SaveEncryptedCV(cvFile)
+	cv.SaveKeys(keysFile)
+
+	restored := securecv.NewSecureCV()
+	if err := restored.Restore(cvFile, keysFile); err != nil {
+		fmt.Printf("❌ Failed to restore: %v\n", err)
+		return
+	}
+
+	failures := 0
+	for field := range cvData {
+		original, _ := cv.GetField(field)
+		decrypted, err := restored.GetField(field)
+		if err != nil || fmt.Sprint(decrypted) != fmt.Sprint(original) {
+			failures++
+		}
+	}
+	if failures == 0 {
+		fmt.Printf("✅ All %d fields decrypt after restore\n", len(cvData))
+	} else {
+		fmt.Printf("❌ %d fields failed to decrypt after restore\n", failures)
+	}
+
+	// Keys loaded first still get wired to their fields
+	keysFirst := securecv.NewSecureCV()
+	keysFirst.LoadKeys(keysFile)
+	keysFirst.LoadEncryptedCV(cvFile)
+	if _, err := keysFirst.GetField("email"); err == nil {
+		fmt.Println("✅ LoadKeys before LoadEncryptedCV also works")
+	} else {
+		fmt.Printf("❌ LoadKeys before LoadEncryptedCV failed: %v\n", err)
+	}
+}
+
+// TestGenerateRandomHex tests random hex string length, charset and uniqueness
+func TestGenerateRandomHex() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: GENERATE RANDOM HEX")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	valid := true
+	for _, n := range []int{0, 1, 7, 16, 33} {
+		value := cryptoutils.GenerateRandomHex(n)
+		if len(value) != n || strings.Trim(value, "0123456789abcdef") != "" {
+			valid = false
+			fmt.Printf("   Bad output for n=%d: %q\n", n, value)
+		}
+	}
+	if valid {
+		fmt.Println("✅ Output has the requested length and lowercase hex charset")
+	} else {
+		fmt.Println("❌ Output length or charset is wrong")
+	}
+
+	if cryptoutils.GenerateRandomHex(32) != cryptoutils.GenerateRandomHex(32) {
+		fmt.Println("✅ Successive calls differ")
+	} else {
+		fmt.Println("❌ Successive calls returned the same value")
+	}
+}
+
+// TestFieldNameBinding tests that a ciphertext cannot be moved to another field
+func TestFieldNameBinding(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: FIELD NAME BINDING")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	cvFile := filepath.Join(dir, "cv.json")
+	keysFile := filepath.Join(dir, "keys.json")
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "single")
+	cv.SaveEncryptedCV(cvFile)
+	cv.SaveKeys(keysFile)
+
+	// Copy the email ciphertext into the phone slot; both share one key
+	var saved models.EncryptedCV
+	fileio.LoadJSON(cvFile, &saved)
+	saved.EncryptedData["phone"] = saved.EncryptedData["email"]
+	fileio.SaveJSON(cvFile, &saved)
+
+	swapped := securecv.NewSecureCV()
+	swapped.LoadEncryptedCV(cvFile)
+	swapped.LoadKeys(keysFile)
+
+	if value, err := swapped.GetField("email"); err == nil && value == cvData["email"] {
+		fmt.Println("✅ Email decrypts in its own slot")
+	} else {
+		fmt.Printf("❌ Email failed in its own slot: %v\n", err)
+	}
+	if _, err := swapped.GetField("phone"); err != nil {
+		fmt.Printf("✅ Email ciphertext rejected as phone: %v\n", err)
+	} else {
+		fmt.Println("❌ Email ciphertext decrypted as phone")
+	}
+}
+
+// TestKeyExpiry tests that keys past their TTL can no longer decrypt
+func TestKeyExpiry(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: KEY EXPIRY")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	keys := cv.KeyChain()
+	topology := cv.Topology()
+
+	if err := keys.SetKeyTTL(topology.Fields["email"], 50*time.Millisecond); err != nil {
+		fmt.Printf("❌ Failed to set key TTL: %v\n", err)
+		return
+	}
+	keys.SetKeyTTL(topology.Fields["phone"], time.Hour)
+
+	if _, err := cv.GetField("email"); err == nil {
+		fmt.Println("✅ Key decrypts before its TTL elapses")
+	} else {
+		fmt.Printf("❌ Key failed before expiry: %v\n", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if _, err := cv.GetField("email"); err != nil && strings.Contains(err.Error(), "field key expired") {
+		fmt.Printf("✅ Expired key rejected: %v\n", err)
+	} else {
+		fmt.Printf("❌ Expired key not rejected: %v\n", err)
+	}
+	if _, err := keys.GetKeyBytes(topology.Fields["email"]); err != nil && err.Error() == "key expired" {
+		fmt.Println("✅ GetKeyBytes reports key expired")
+	} else {
+		fmt.Printf("❌ GetKeyBytes returned: %v\n", err)
+	}
+	if _, err := cv.GetField("phone"); err == nil {
+		fmt.Println("✅ Non-expired key still works")
+	} else {
+		fmt.Printf("❌ Non-expired key failed: %v\n", err)
+	}
+}
+
+// TestFieldBundle tests sharing a field with a recipient who has no SecureCV
+func TestFieldBundle(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: FIELD BUNDLE")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	owner := securecv.NewSecureCV()
+	owner.LoadCV(cvData, "single")
+
+	bundle, err := owner.ExportFieldBundle("email")
+	if err != nil {
+		fmt.Printf("❌ Failed to export bundle: %v\n", err)
+		return
+	}
+
+	// The recipient only sees the serialized bundle
+	wire, _ := json.Marshal(bundle)
+	var received models.FieldBundle
+	if err := json.Unmarshal(wire, &received); err != nil {
+		fmt.Printf("❌ Failed to parse bundle: %v\n", err)
+		return
+	}
+
+	value, err := securecv.DecryptField(received.Encrypted, received.Key)
+	if err == nil && value == cvData["email"] {
+		fmt.Printf("✅ Recipient decrypted shared field: %v\n", value)
+	} else {
+		fmt.Printf("❌ Recipient failed to decrypt: %v, %v\n", value, err)
+	}
+
+	// The key is scoped to email, so it cannot open other fields it encrypts
+	phone, _ := owner.ExportFieldBundle("phone")
+	if _, err := securecv.DecryptField(phone.Encrypted, received.Key); err != nil {
+		fmt.Println("✅ Bundle key does not open other fields")
+	} else {
+		fmt.Println("❌ Bundle key opened a field it was not shared for")
+	}
+
+	badKey := *received.Key
+	badKey.Key = base64.StdEncoding.EncodeToString([]byte("short"))
+	if _, err := securecv.DecryptField(received.Encrypted, &badKey); err != nil {
+		fmt.Printf("✅ Invalid key rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Invalid key accepted")
+	}
+}
+
+// TestFieldGroups tests loading with named groups of fields sharing a key
+func TestFieldGroups(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: FIELD GROUPS")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	groups := map[string][]string{
+		"contact":  {"email", "phone", "github"},
+		"identity": {"name", "current_position"},
+	}
+
+	cv := securecv.NewSecureCV()
+	if err := cv.LoadCVWithGroups(cvData, groups); err != nil {
+		fmt.Printf("❌ Failed to load with groups: %v\n", err)
+		return
+	}
+
+	fields := cv.Topology().Fields
+	if fields["email"] == fields["phone"] && fields["name"] == fields["current_position"] && fields["email"] != fields["name"] {
+		fmt.Println("✅ Group members share a key; groups have distinct keys")
+	} else {
+		fmt.Println("❌ Group key assignment is wrong")
+	}
+	if fields["skills"] != fields["email"] && fields["skills"] != fields["name"] {
+		fmt.Println("✅ Ungrouped field has its own key")
+	} else {
+		fmt.Println("❌ Ungrouped field shares a group key")
+	}
+	if _, loaded := fields["github"]; !loaded && len(fields) == len(cvData) {
+		fmt.Println("✅ Grouped field absent from CV data was skipped")
+	} else {
+		fmt.Println("❌ Absent grouped field handling is wrong")
+	}
+
+	shareable, _ := cv.GetShareableKey("phone")
+	if shareable != nil && reflect.DeepEqual(shareable.Fields, []string{"email", "phone"}) {
+		fmt.Printf("✅ Shareable key lists sibling fields: %v\n", shareable.Fields)
+	} else {
+		fmt.Printf("❌ Shareable key fields: %v\n", shareable)
+	}
+
+	overlapping := map[string][]string{"a": {"email"}, "b": {"email", "phone"}}
+	if err := securecv.NewSecureCV().LoadCVWithGroups(cvData, overlapping); err != nil {
+		fmt.Printf("✅ Field in two groups rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Field in two groups accepted")
+	}
+}
+
+// failingWAL is a WAL store that accepts a fixed number of writes and then
+// fails, simulating a disk that fills up mid-operation
+type failingWAL struct {
+	bytes.Buffer
+	remaining int
+}
+
+func (w *failingWAL) Write(p []byte) (int, error) {
+	if w.remaining <= 0 {
+		return 0, fmt.Errorf("no space left on device")
+	}
+	w.remaining--
+	return w.Buffer.Write(p)
+}
+
+func (w *failingWAL) Sync() error  { return nil }
+func (w *failingWAL) Close() error { return nil }
+
+// TestRotateAllKeys tests bulk rotation of every field
+func TestRotateAllKeys(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: ROTATE ALL KEYS")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	for _, mode := range []string{"single", "multi"} {
+		cv := securecv.NewSecureCV()
+		cv.LoadCV(cvData, mode)
+		before := cv.Topology()
+
+		newKeys, err := cv.RotateAllKeys()
+		if err != nil {
+			fmt.Printf("❌ %s mode rotation failed: %v\n", mode, err)
+			continue
+		}
+		after := cv.Topology()
+
+		changed, intact := true, true
+		for field, value := range cvData {
+			if newKeys[field] == before.Fields[field] || after.Fields[field] != newKeys[field] {
+				changed = false
+			}
+			if decrypted, err := cv.GetField(field); err != nil || decrypted != value {
+				intact = false
+			}
+		}
+		if changed && intact && len(after.Groups) == len(before.Groups) {
+			fmt.Printf("✅ %s mode: all %d fields on new keys, plaintext and topology preserved\n", mode, len(newKeys))
+		} else {
+			fmt.Printf("❌ %s mode: changed=%v intact=%v groups %d -> %d\n", mode, changed, intact, len(before.Groups), len(after.Groups))
+		}
+	}
+
+	// A field that cannot be decrypted aborts the whole rotation
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	before := cv.Topology()
+	cv.RevokeFieldKey("email")
+	if _, err := cv.RotateAllKeys(); err != nil && reflect.DeepEqual(cv.Topology().Fields, before.Fields) {
+		fmt.Printf("✅ Failed rotation left every field on its old key: %v\n", err)
+	} else {
+		fmt.Printf("❌ Failed rotation changed keys or did not fail: %v\n", err)
+	}
+
+	// A store failure partway through leaves the key chain as it was
+	cv = securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	before = cv.Topology()
+	size, current := cv.KeyChain().Size(), cv.KeyChain().GetCurrentKey().KeyID
+	// Allow the first field's begin and commit records, then fail
+	cv.EnableWALStore(&failingWAL{remaining: 2})
+	_, err := cv.RotateAllKeys()
+	cv.DisableWAL()
+	if err != nil && cv.KeyChain().Size() == size && cv.KeyChain().GetCurrentKey().KeyID == current &&
+		reflect.DeepEqual(cv.Topology().Fields, before.Fields) {
+		fmt.Printf("✅ Failed store removed the new keys and kept the current key: %v\n", err)
+	} else {
+		fmt.Printf("❌ After failed store: err %v, keys %d -> %d, current %s -> %s\n", err,
+			size, cv.KeyChain().Size(), models.ShortID(current, 8), models.ShortID(cv.KeyChain().GetCurrentKey().KeyID, 8))
+	}
+	if email, err := cv.GetField("email"); err == nil && email == cvData["email"] {
+		fmt.Println("✅ Fields still decrypt after the rollback")
+	} else {
+		fmt.Printf("❌ Field unreadable after rollback: %v\n", err)
+	}
+}
+
+// TestKeyHistory tests walking a field's chain of rotated keys
+func TestKeyHistory(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: KEY HISTORY")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+
+	expected := []string{cv.Topology().Fields["email"]}
+	for i := 0; i < 3; i++ {
+		newKeyID, err := cv.RotateFieldKey("email")
+		if err != nil {
+			fmt.Printf("❌ Rotation %d failed: %v\n", i+1, err)
+			return
+		}
+		expected = append([]string{newKeyID}, expected...)
+	}
+
+	history, err := cv.GetKeyHistory("email")
+	if err == nil && reflect.DeepEqual(history, expected[1:]) {
+		fmt.Printf("✅ History has %d predecessors, most recent first\n", len(history))
+	} else {
+		fmt.Printf("❌ History %v, expected %v (%v)\n", history, expected[1:], err)
+	}
+
+	if history, _ := cv.GetKeyHistory("phone"); len(history) == 0 {
+		fmt.Println("✅ Unrotated field has empty history")
+	} else {
+		fmt.Printf("❌ Unrotated field history: %v\n", history)
+	}
+}
+
+// TestAtomicSave tests that SaveJSON replaces files atomically
+func TestAtomicSave() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: ATOMIC SAVE")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	large := make(map[string]string)
+	for i := 0; i < 20000; i++ {
+		large[fmt.Sprintf("field_%05d", i)] = strings.Repeat("x", 200)
+	}
+
+	// Concurrent saves to different files must not share temp names
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = fileio.SaveJSON(filepath.Join(dir, fmt.Sprintf("large_%d.json", i)), large)
+		}(i)
+	}
+	wg.Wait()
+
+	parsed := 0
+	for i, err := range errs {
+		var loaded map[string]string
+		if err == nil && fileio.LoadJSON(filepath.Join(dir, fmt.Sprintf("large_%d.json", i)), &loaded) == nil && len(loaded) == len(large) {
+			parsed++
+		}
+	}
+	if parsed == len(errs) {
+		fmt.Printf("✅ %d concurrent large saves all parse\n", parsed)
+	} else {
+		fmt.Printf("❌ Only %d of %d saves parse\n", parsed, len(errs))
+	}
+
+	entries, _ := os.ReadDir(dir)
+	info, statErr := os.Stat(filepath.Join(dir, "large_0.json"))
+	if len(entries) == len(errs) && statErr == nil && info.Mode().Perm() == 0644 {
+		fmt.Println("✅ No temp files left behind; permissions are 0644")
+	} else {
+		fmt.Printf("❌ Directory has %d entries (want %d) or wrong permissions\n", len(entries), len(errs))
+	}
+}
+
+// TestStreamEncryption tests chunked encryption of large values
+func TestStreamEncryption() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: STREAM ENCRYPTION")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	key := cryptoutils.GenerateRandomBytes(32)
+	blob := make([]byte, 3*1024*1024+123)
+	rand.Read(blob)
+
+	var encrypted bytes.Buffer
+	if err := cryptoutils.EncryptStream(bytes.NewReader(blob), &encrypted, key); err != nil {
+		fmt.Printf("❌ Failed to encrypt stream: %v\n", err)
+		return
+	}
+
+	var decrypted bytes.Buffer
+	if err := cryptoutils.DecryptStream(bytes.NewReader(encrypted.Bytes()), &decrypted, key); err == nil && bytes.Equal(decrypted.Bytes(), blob) {
+		fmt.Printf("✅ %d-byte stream round-trips\n", len(blob))
+	} else {
+		fmt.Printf("❌ Stream round-trip failed: %v\n", err)
+	}
+
+	// Cut exactly at a frame boundary as well as mid-frame
+	for _, cut := range []int{encrypted.Len() - 1, 7 + 5 + 64*1024 + 16} {
+		err := cryptoutils.DecryptStream(bytes.NewReader(encrypted.Bytes()[:cut]), io.Discard, key)
+		if err != nil && strings.Contains(err.Error(), "authentication failed") {
+			fmt.Printf("✅ Truncated stream rejected at %d bytes\n", cut)
+		} else {
+			fmt.Printf("❌ Truncated stream at %d bytes: %v\n", cut, err)
+		}
+	}
+
+	portfolio := strings.Repeat("portfolio data ", 100000)
+	cv := securecv.NewSecureCV()
+	if err := cv.LoadFieldFromReader("portfolio", strings.NewReader(portfolio)); err != nil {
+		fmt.Printf("❌ Failed to load field from reader: %v\n", err)
+		return
+	}
+	if value, err := cv.GetField("portfolio"); err == nil && value == portfolio {
+		fmt.Println("✅ Field loaded from reader decrypts via GetField")
+	} else {
+		fmt.Printf("❌ Streamed field failed to decrypt: %v\n", err)
+	}
+
+	// Revoke the key while the reader is blocked mid-stream
+	revoked := securecv.NewSecureCV()
+	revoked.LoadCV(map[string]interface{}{"name": "Violet K."}, "single")
+	keyID := revoked.KeyChain().GetCurrentKey().KeyID
+	pr, pw := io.Pipe()
+	loaded := make(chan error, 1)
+	go func() { loaded <- revoked.LoadFieldFromReader("portfolio", pr) }()
+	pw.Write([]byte(portfolio[:1024]))
+	revoked.RevokeKey(keyID)
+	pw.Write([]byte(portfolio[1024:]))
+	pw.Close()
+	if err := <-loaded; err != nil {
+		fmt.Printf("✅ Key revoked mid-stream rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Field stored under a key revoked mid-stream")
+	}
+	if _, err := revoked.GetField("portfolio"); err != nil {
+		fmt.Println("✅ Nothing stored after the mid-stream revocation")
+	} else {
+		fmt.Println("❌ Field readable after the mid-stream revocation")
+	}
+}
+
+// TestLoadCVDataValidated tests required-field and null checks on CV data
+func TestLoadCVDataValidated(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: LOAD CV DATA VALIDATED")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	required := []string{"name", "email", "phone"}
+
+	validFile := filepath.Join(dir, "valid.json")
+	fileio.SaveJSON(validFile, cvData)
+	if loaded, err := fileio.LoadCVDataValidated(validFile, required); err == nil && len(loaded) == len(cvData) {
+		fmt.Println("✅ CV data with all required fields loads")
+	} else {
+		fmt.Printf("❌ Valid CV data rejected: %v\n", err)
+	}
+
+	invalid := make(map[string]interface{}, len(cvData))
+	for field, value := range cvData {
+		invalid[field] = value
+	}
+	delete(invalid, "email")
+	invalid["phone"] = nil
+	invalidFile := filepath.Join(dir, "invalid.json")
+	fileio.SaveJSON(invalidFile, invalid)
+
+	_, err = fileio.LoadCVDataValidated(invalidFile, required)
+	if err != nil && strings.Contains(err.Error(), "missing required fields: email") && strings.Contains(err.Error(), "null fields: phone") {
+		fmt.Printf("✅ Missing and null fields named: %v\n", err)
+	} else {
+		fmt.Printf("❌ Unexpected validation result: %v\n", err)
+	}
+}
+
+// TestExportFields tests exporting a subset of fields with only their keys
+func TestExportFields(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: EXPORT FIELDS")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	requested := []string{"name", "skills", "current_position"}
+
+	multi := securecv.NewSecureCV()
+	multi.LoadCV(cvData, "multi")
+	manifest, encrypted, err := multi.ExportFields(requested)
+	if err != nil {
+		fmt.Printf("❌ Failed to export fields: %v\n", err)
+		return
+	}
+
+	topology := multi.Topology()
+	leaked := 0
+	for field, keyID := range topology.Fields {
+		_, hasKey := manifest.Keys[keyID]
+		_, hasData := encrypted[field]
+		isRequested := field == "name" || field == "skills" || field == "current_position"
+		if !isRequested && (hasKey || hasData) {
+			leaked++
+		}
+	}
+	if leaked == 0 && len(manifest.Keys) == len(requested) && len(encrypted) == len(requested) {
+		fmt.Printf("✅ Manifest holds exactly %d keys for the requested fields\n", len(manifest.Keys))
+	} else {
+		fmt.Printf("❌ Export leaked %d fields or has %d keys\n", leaked, len(manifest.Keys))
+	}
+
+	single := securecv.NewSecureCV()
+	single.LoadCV(cvData, "single")
+	manifest, _, _ = single.ExportFields(requested)
+	for _, key := range manifest.Keys {
+		if len(manifest.Keys) == 1 && len(key.Fields) == len(requested) {
+			fmt.Printf("✅ Shared key exported once listing only %v\n", key.Fields)
+		} else {
+			fmt.Printf("❌ Single-mode manifest has %d keys, fields %v\n", len(manifest.Keys), key.Fields)
+		}
+	}
+
+	if _, _, err := single.ExportFields([]string{"name", "no_such_field"}); err != nil {
+		fmt.Printf("✅ Unknown field rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Unknown field accepted")
+	}
+}
+
+// TestFromManifest tests rebuilding a SecureCV from an exported subset
+func TestFromManifest(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: FROM MANIFEST")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	requested := []string{"name", "skills", "current_position"}
+
+	owner := securecv.NewSecureCV()
+	owner.LoadCV(cvData, "multi")
+	manifest, encrypted, err := owner.ExportFields(requested)
+	if err != nil {
+		fmt.Printf("❌ Failed to export fields: %v\n", err)
+		return
+	}
+
+	recipient, err := securecv.FromManifest(manifest, encrypted)
+	if err != nil {
+		fmt.Printf("❌ Failed to build from manifest: %v\n", err)
+		return
+	}
+
+	decrypted := 0
+	for _, field := range requested {
+		if value, err := recipient.GetField(field); err == nil && value == cvData[field] {
+			decrypted++
+		}
+	}
+	if decrypted == len(requested) {
+		fmt.Printf("✅ Recipient decrypted all %d exported fields\n", decrypted)
+	} else {
+		fmt.Printf("❌ Recipient decrypted %d of %d fields\n", decrypted, len(requested))
+	}
+
+	if _, err := recipient.GetField("email"); err != nil && strings.Contains(err.Error(), "not found") {
+		fmt.Println("✅ Non-exported field reports not found")
+	} else {
+		fmt.Printf("❌ Non-exported field: %v\n", err)
+	}
+
+	delete(manifest.Keys, manifest.FieldMap["skills"])
+	if _, err := securecv.FromManifest(manifest, encrypted); err != nil {
+		fmt.Printf("✅ Missing key rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Manifest with a missing key accepted")
+	}
+}
+
+// TestLoadModes tests that LoadCV accepts only known modes
+func TestLoadModes(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: LOAD MODES")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	for _, mode := range []string{"single ", "per-field", ""} {
+		cv := securecv.NewSecureCV()
+		err := cv.LoadCV(cvData, mode)
+		if err != nil && err.Error() == fmt.Sprintf("unknown mode %q", mode) && len(cv.Topology().Fields) == 0 {
+			fmt.Printf("✅ Mode %q rejected: %v\n", mode, err)
+		} else {
+			fmt.Printf("❌ Mode %q: %v\n", mode, err)
+		}
+	}
+
+	for mode, wantKeys := range map[string]int{"single": 1, "multi": len(cvData)} {
+		cv := securecv.NewSecureCV()
+		if err := cv.LoadCV(cvData, mode); err == nil && len(cv.Topology().Groups) == wantKeys {
+			fmt.Printf("✅ Mode %q still works with %d keys\n", mode, wantKeys)
+		} else {
+			fmt.Printf("❌ Mode %q: %v\n", mode, err)
+		}
+	}
+}
+
+// TestParallelLoad benchmarks serial against parallel multi-mode loading and
+// checks every field still decrypts, and that reloads keep field metadata
+func TestParallelLoad(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: PARALLEL LOAD")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	manyFieldsData := make(map[string]interface{})
+	for i := 0; i < 1000; i++ {
+		manyFieldsData[fmt.Sprintf("field_%d", i)] = strings.Repeat(fmt.Sprintf("Value for field %d ", i), 20)
+	}
+
+	parallel := max(4, runtime.NumCPU())
+	timings := make(map[int]time.Duration)
+	for _, workers := range []int{1, parallel} {
+		cv := securecv.NewSecureCV()
+		cv.SetParallelism(workers)
+
+		start := time.Now()
+		err := cv.LoadCV(manyFieldsData, "multi")
+		timings[workers] = time.Since(start)
+		if err != nil {
+			fmt.Printf("❌ Load with %d workers failed: %v\n", workers, err)
+			return
+		}
+
+		failures := 0
+		for field, value := range manyFieldsData {
+			if decrypted, err := cv.GetField(field); err != nil || decrypted != value {
+				failures++
+			}
+		}
+		if failures == 0 && cv.KeyChain().Size() == len(manyFieldsData) {
+			fmt.Printf("✅ %d workers: all %d fields decrypt, one key each\n", workers, len(manyFieldsData))
+		} else {
+			fmt.Printf("❌ %d workers: %d fields failed to decrypt\n", workers, failures)
+		}
+	}
+
+	fmt.Printf("   Serial: %v, parallel (%d workers): %v\n", timings[1], parallel, timings[parallel])
+
+	// Reloading keeps a field's metadata whichever path the load takes
+	labels := map[string]string{"classification": "confidential"}
+	for _, workers := range []int{1, parallel} {
+		cv := securecv.NewSecureCV()
+		cv.SetParallelism(workers)
+		cv.LoadCV(cvData, "multi")
+		cv.SetFieldMetadata("email", labels)
+		if err := cv.LoadCV(cvData, "multi"); err != nil {
+			fmt.Printf("❌ Reload with %d workers failed: %v\n", workers, err)
+			continue
+		}
+		metadata, err := cv.GetFieldMetadata("email")
+		if email, getErr := cv.GetField("email"); err == nil && getErr == nil && email == cvData["email"] && reflect.DeepEqual(metadata, labels) {
+			fmt.Printf("✅ %d workers: reload keeps metadata bound to the field\n", workers)
+		} else {
+			fmt.Printf("❌ %d workers: metadata %v after reload (%v, %v)\n", workers, metadata, err, getErr)
+		}
+	}
+}
+
+// TestRotateIfOlderThan tests age-gated rotation
+func TestRotateIfOlderThan(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: ROTATE IF OLDER THAN")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	originalKeyID := cv.Topology().Fields["email"]
+
+	rotated, keyID, err := cv.RotateIfOlderThan("email", 24*time.Hour)
+	if err == nil && !rotated && keyID == originalKeyID {
+		fmt.Println("✅ Fresh key left alone")
+	} else {
+		fmt.Printf("❌ Fresh key: rotated=%v keyID=%s err=%v\n", rotated, keyID, err)
+	}
+
+	// Backdate the key past the threshold
+	cv.KeyChain().GetNode(originalKeyID).Timestamp = time.Now().Add(-48 * time.Hour).Unix()
+
+	rotated, keyID, err = cv.RotateIfOlderThan("email", 24*time.Hour)
+	value, _ := cv.GetField("email")
+	if err == nil && rotated && keyID != originalKeyID && cv.Topology().Fields["email"] == keyID && value == cvData["email"] {
+		fmt.Println("✅ Backdated key rotated; field still decrypts")
+	} else {
+		fmt.Printf("❌ Backdated key: rotated=%v keyID=%s err=%v\n", rotated, keyID, err)
+	}
+}
+
+// TestKeyPEM tests exporting keys as PEM blocks and decoding them back
+func TestKeyPEM(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: KEY PEM")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	cv.RevokeFieldKey("email")
+
+	var exported strings.Builder
+	if err := cv.ExportKeysPEM(&exported); err != nil {
+		fmt.Printf("❌ Failed to export PEM: %v\n", err)
+		return
+	}
+	if strings.HasPrefix(exported.String(), "-----BEGIN FIELD CIPHER KEY-----\nKeyID: ") {
+		fmt.Println("✅ Export uses FIELD CIPHER KEY blocks with a KeyID header")
+	} else {
+		fmt.Println("❌ Unexpected PEM layout")
+	}
+
+	matched := 0
+	rest := exported.String()
+	for strings.TrimSpace(rest) != "" {
+		keyID, key, remaining, err := cryptoutils.DecodeKeyPEM(rest)
+		if err != nil {
+			fmt.Printf("❌ Failed to decode block: %v\n", err)
+			return
+		}
+		if node := cv.KeyChain().GetNode(keyID); node != nil && !node.Revoked && bytes.Equal(node.KeyBytes, key) {
+			matched++
+		}
+		rest = remaining
+	}
+	if matched == len(cvData)-1 {
+		fmt.Printf("✅ %d active keys round-trip; revoked key omitted\n", matched)
+	} else {
+		fmt.Printf("❌ %d keys round-tripped, expected %d\n", matched, len(cvData)-1)
+	}
+
+	if _, _, _, err := cryptoutils.DecodeKeyPEM(cryptoutils.EncodeKeyPEM("short", []byte("not a key"))); err != nil {
+		fmt.Printf("✅ Invalid key size rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Invalid key size accepted")
+	}
+}
+
+// TestTamperDetection tests that a single flipped byte is reported for
+// exactly the tampered field
+func TestTamperDetection(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: TAMPER DETECTION")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	cvFile := filepath.Join(dir, "cv.json")
+	keysFile := filepath.Join(dir, "keys.json")
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "single")
+	cv.SaveEncryptedCV(cvFile)
+	cv.SaveKeys(keysFile)
+
+	if _, err := cv.VerifyIntegrity(); err == nil {
+		fmt.Println("✅ Untouched CV passes integrity check")
+	} else {
+		fmt.Printf("❌ Untouched CV failed: %v\n", err)
+	}
+
+	if err := flipCiphertextByte(cvFile, "skills"); err != nil {
+		fmt.Printf("❌ Failed to corrupt skills: %v\n", err)
+		return
+	}
+
+	loaded := securecv.NewSecureCV()
+	loaded.LoadEncryptedCV(cvFile)
+	loaded.LoadKeys(keysFile)
+	results, err := loaded.VerifyIntegrity()
+
+	failed := make([]string, 0)
+	for field, fieldErr := range results {
+		if fieldErr != nil {
+			failed = append(failed, field)
+		}
+	}
+	if err != nil && len(results) == len(cvData) && reflect.DeepEqual(failed, []string{"skills"}) {
+		fmt.Printf("✅ Only the tampered field reported: %v\n", results["skills"])
+	} else {
+		fmt.Printf("❌ Failed fields %v, summary %v\n", failed, err)
+	}
+}
+
+// TestCLI tests the command line encrypt and decrypt paths
+func TestCLI(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: CLI")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	inFile := filepath.Join(dir, "cv.json")
+	cvFile := filepath.Join(dir, "enc.json")
+	keysFile := filepath.Join(dir, "keys.json")
+	fileio.SaveJSON(inFile, cvData)
+
+	opts, err := cli.Parse([]string{"-in", inFile, "-mode", "multi", "-out-cv", cvFile, "-out-keys", keysFile})
+	if err == nil {
+		err = cli.Execute(opts, io.Discard)
+	}
+	if err == nil && fileio.FileExists(cvFile) && fileio.FileExists(keysFile) {
+		fmt.Println("✅ CLI encrypted CV and wrote keys")
+	} else {
+		fmt.Printf("❌ CLI encrypt failed: %v\n", err)
+		return
+	}
+
+	var stdout bytes.Buffer
+	opts, err = cli.Parse([]string{"-decrypt", "-cv", cvFile, "-keys", keysFile, "-field", "email"})
+	if err == nil {
+		err = cli.Execute(opts, &stdout)
+	}
+	if err == nil && stdout.String() == fmt.Sprintf("%v\n", cvData["email"]) {
+		fmt.Printf("✅ CLI decrypted field: %s", stdout.String())
+	} else {
+		fmt.Printf("❌ CLI decrypt failed: %q, %v\n", stdout.String(), err)
+	}
+
+	for _, args := range [][]string{{}, {"-decrypt", "-cv", cvFile}, {"-in", inFile}} {
+		if _, err := cli.Parse(args); err != nil {
+			fmt.Printf("✅ Incomplete flags %v rejected: %v\n", args, err)
+		} else {
+			fmt.Printf("❌ Incomplete flags %v accepted\n", args)
+		}
+	}
+}
+
+// TestAccessStats tests per-field decryption counters
+func TestAccessStats(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: ACCESS STATS")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+
+	before := time.Now()
+	for i := 0; i < 3; i++ {
+		cv.GetField("email")
+	}
+	cv.GetField("no_such_field")
+
+	stats := cv.GetAccessStats()
+	email := stats["email"]
+	if email.Count == 3 && !email.LastAccess.Before(before) && time.Since(email.LastAccess) < time.Minute {
+		fmt.Printf("✅ Email accessed %d times, last at %v\n", email.Count, email.LastAccess.Format(time.RFC3339))
+	} else {
+		fmt.Printf("❌ Email stats: %+v\n", email)
+	}
+
+	phone, listed := stats["phone"]
+	if listed && phone.Count == 0 && phone.LastAccess.IsZero() && len(stats) == len(cvData) {
+		fmt.Println("✅ Never-accessed fields report zero")
+	} else {
+		fmt.Printf("❌ Phone stats: %+v (listed=%v)\n", phone, listed)
+	}
+}
+
+// TestKeyFingerprint tests key fingerprints on export and import
+func TestKeyFingerprint(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: KEY FINGERPRINT")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	key := cryptoutils.GenerateRandomBytes(32)
+	copied := append([]byte{}, key...)
+	other := cryptoutils.GenerateRandomBytes(32)
+	if cryptoutils.KeyFingerprint(key) == cryptoutils.KeyFingerprint(copied) && cryptoutils.KeyFingerprint(key) != cryptoutils.KeyFingerprint(other) {
+		fmt.Printf("✅ Fingerprint stable for identical keys, distinct otherwise: %s\n", cryptoutils.KeyFingerprint(key))
+	} else {
+		fmt.Println("❌ Fingerprint not stable or not distinct")
+	}
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	shareable, _ := cv.GetShareableKey("email")
+	keyBytes, _ := base64.StdEncoding.DecodeString(shareable.Key)
+	if shareable.Fingerprint == cryptoutils.KeyFingerprint(keyBytes) {
+		fmt.Println("✅ Shareable key carries the fingerprint of its key bytes")
+	} else {
+		fmt.Println("❌ Shareable key fingerprint missing or wrong")
+	}
+
+	bundle, _ := cv.ExportFieldBundle("email")
+	bundle.Key.Fingerprint = cryptoutils.KeyFingerprint(other)
+	if _, err := securecv.DecryptField(bundle.Encrypted, bundle.Key); err != nil && strings.Contains(err.Error(), "fingerprint") {
+		fmt.Printf("✅ Mismatched fingerprint rejected: %v\n", err)
+	} else {
+		fmt.Printf("❌ Mismatched fingerprint: %v\n", err)
+	}
+}
+
+// TestKeyZeroize tests that key bytes are wiped on revocation and shutdown
+func TestKeyZeroize(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: KEY ZEROIZE")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	allZero := func(b []byte) bool {
+		for _, v := range b {
+			if v != 0 {
+				return false
+			}
+		}
+		return len(b) > 0
+	}
+
+	kc := keychain.NewKeyChain()
+	revoked := kc.CreateKey()
+	kept := kc.CreateKey()
+	revokedBytes, keptBytes := revoked.KeyBytes, kept.KeyBytes
+
+	kc.RevokeKey(revoked.KeyID)
+	if allZero(revokedBytes) && !allZero(keptBytes) {
+		fmt.Println("✅ Revocation zeroed the key's backing array")
+	} else {
+		fmt.Println("❌ Revoked key bytes still present")
+	}
+
+	cleaned := kc.CreateKey()
+	cleanedBytes := cleaned.KeyBytes
+	kc.RevokeKey(cleaned.KeyID)
+	cleaned.Timestamp = time.Now().Add(-time.Hour).Unix()
+	if kc.CleanupRevokedKeys(time.Minute) == 1 && kc.GetNode(cleaned.KeyID) == nil && allZero(cleanedBytes) {
+		fmt.Println("✅ Cleanup removed the old revoked key with zeroed bytes")
+	} else {
+		fmt.Println("❌ Cleanup did not remove or wipe revoked keys")
+	}
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	captured := make([][]byte, 0)
+	for _, node := range cv.KeyChain().GetAllKeys() {
+		captured = append(captured, node.KeyBytes)
+	}
+	cv.KeyChain().SecureWipe()
+	wiped := 0
+	for _, b := range captured {
+		if allZero(b) {
+			wiped++
+		}
+	}
+	if wiped == len(captured) {
+		fmt.Printf("✅ SecureWipe zeroed all %d keys\n", wiped)
+	} else {
+		fmt.Printf("❌ SecureWipe zeroed %d of %d keys\n", wiped, len(captured))
+	}
+}
+
+// repeatingReader yields the same bytes forever, simulating a broken RNG
+type repeatingReader struct{}
+
+func (repeatingReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0x42
+	}
+	return len(p), nil
+}
+
+// TestNonceReuseDetection tests that a repeated nonce under one key is rejected
+func TestNonceReuseDetection() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: NONCE REUSE DETECTION")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cryptoutils.SetRandReader(repeatingReader{})
+	defer cryptoutils.SetRandReader(nil)
+
+	cv := securecv.NewSecureCV()
+	cv.KeyChain().SetNonceTracking(true)
+	if err := cv.LoadCV(map[string]interface{}{"name": "Violet K."}, "single"); err != nil {
+		fmt.Printf("❌ First encryption failed: %v\n", err)
+		return
+	}
+
+	err := cv.LoadCV(map[string]interface{}{"email": "Violet.tech@Violet.com"}, "single")
+	if err != nil && strings.Contains(err.Error(), "nonce reuse detected") {
+		fmt.Printf("✅ Repeated nonce under the same key rejected: %v\n", err)
+	} else {
+		fmt.Printf("❌ Repeated nonce not detected: %v\n", err)
+	}
+
+	if err := cv.LoadCV(map[string]interface{}{"phone": "555"}, "multi"); err == nil {
+		fmt.Println("✅ Same nonce under a different key is allowed")
+	} else {
+		fmt.Printf("❌ Different key rejected: %v\n", err)
+	}
+
+	untracked := securecv.NewSecureCV()
+	untracked.LoadCV(map[string]interface{}{"name": "Violet K."}, "single")
+	if err := untracked.LoadCV(map[string]interface{}{"email": "x"}, "single"); err == nil {
+		fmt.Println("✅ Tracking is off by default")
+	} else {
+		fmt.Printf("❌ Untracked chain rejected encryption: %v\n", err)
+	}
+}
+
+// TestGetFields tests bulk decryption with per-field errors
+func TestGetFields(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: GET FIELDS")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+
+	values, errs := cv.GetFields([]string{"name", "email", "nonexistent", "also_missing"})
+	if len(values) == 2 && values["name"] == cvData["name"] && values["email"] == cvData["email"] {
+		fmt.Printf("✅ Existing fields decrypted: %v\n", values["name"])
+	} else {
+		fmt.Printf("❌ Values: %v\n", values)
+	}
+	if len(errs) == 2 && errs["nonexistent"] != nil && errs["also_missing"] != nil {
+		fmt.Printf("✅ Missing fields reported separately: %v\n", errs["nonexistent"])
+	} else {
+		fmt.Printf("❌ Errors: %v\n", errs)
+	}
+}
+
+// TestRemoveField tests deleting a field and its key mapping
+func TestRemoveField(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: REMOVE FIELD")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "single")
+	owner := cv.KeyChain().GetNode(cv.Topology().Fields["phone"])
+
+	if err := cv.RemoveField("phone"); err != nil {
+		fmt.Printf("❌ Failed to remove field: %v\n", err)
+		return
+	}
+	if _, err := cv.GetField("phone"); err != nil && strings.Contains(err.Error(), "not found") {
+		fmt.Println("✅ Removed field reports not found")
+	} else {
+		fmt.Printf("❌ Removed field: %v\n", err)
+	}
+	if !owner.EncryptedFields["phone"] && len(owner.EncryptedFields) == len(cvData)-1 && !owner.Revoked {
+		fmt.Println("✅ Owning key no longer lists the field and still protects the rest")
+	} else {
+		fmt.Printf("❌ Owning key fields: %d, revoked %v\n", len(owner.EncryptedFields), owner.Revoked)
+	}
+	if err := cv.RemoveField("phone"); err != nil && strings.Contains(err.Error(), "not found") {
+		fmt.Println("✅ Removing an unknown field reports not found")
+	} else {
+		fmt.Printf("❌ Second removal: %v\n", err)
+	}
+
+	multi := securecv.NewSecureCV()
+	multi.LoadCV(cvData, "multi")
+	multi.SetRevokeOrphanedKeys(true)
+	current := multi.KeyChain().GetCurrentKey()
+	var currentField, otherField string
+	for field, keyID := range multi.Topology().Fields {
+		if keyID == current.KeyID {
+			currentField = field
+		} else {
+			otherField = field
+		}
+	}
+	otherKey := multi.KeyChain().GetNode(multi.Topology().Fields[otherField])
+	multi.RemoveField(otherField)
+	multi.RemoveField(currentField)
+	if otherKey.Revoked && !current.Revoked {
+		fmt.Println("✅ Orphaned key revoked when enabled; current key kept")
+	} else {
+		fmt.Printf("❌ Orphaned key revoked %v, current key revoked %v\n", otherKey.Revoked, current.Revoked)
+	}
+}
+
+// TestSetField tests adding and overwriting fields after load
+func TestSetField(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: SET FIELD")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	single := securecv.NewSecureCV()
+	single.LoadCV(cvData, "single")
+	sharedKey := single.KeyChain().GetCurrentKey().KeyID
+
+	if err := single.SetField("github", "https://github.com/violet", "single"); err != nil {
+		fmt.Printf("❌ Failed to add field: %v\n", err)
+		return
+	}
+	if value, err := single.GetField("github"); err == nil && value == "https://github.com/violet" && single.Topology().Fields["github"] == sharedKey {
+		fmt.Println("✅ New field added under the shared key in single mode")
+	} else {
+		fmt.Printf("❌ New field: %v, %v\n", value, err)
+	}
+
+	multi := securecv.NewSecureCV()
+	multi.LoadCV(cvData, "multi")
+	oldKeyID := multi.Topology().Fields["email"]
+	oldKey := multi.KeyChain().GetNode(oldKeyID)
+
+	multi.SetAllowOverwrite(true)
+	if err := multi.SetField("email", "new.address@example.com", "multi"); err != nil {
+		fmt.Printf("❌ Failed to overwrite field: %v\n", err)
+		return
+	}
+	newKeyID := multi.Topology().Fields["email"]
+	if value, _ := multi.GetField("email"); value == "new.address@example.com" && newKeyID != oldKeyID && !oldKey.EncryptedFields["email"] {
+		fmt.Println("✅ Overwritten field moved to a fresh key with the new value")
+	} else {
+		fmt.Printf("❌ Overwrite: %v under %s\n", value, newKeyID)
+	}
+
+	if err := multi.SetField("email", "x", "per-field"); err != nil {
+		fmt.Printf("✅ Unknown mode rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Unknown mode accepted")
+	}
+}
+
+// TestExplicitKeyIDs tests explicit and derived key IDs
+func TestExplicitKeyIDs() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: EXPLICIT KEY IDS")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	kc := keychain.NewKeyChain()
+	ids := []string{"imported-alpha", "imported-beta"}
+	for _, id := range ids {
+		if _, err := kc.CreateKeyWithID(id); err != nil {
+			fmt.Printf("❌ Failed to create key %s: %v\n", id, err)
+			return
+		}
+	}
+	if kc.GetNode(ids[0]) != nil && kc.GetNode(ids[1]) != nil && kc.GetCurrentKey().KeyID == ids[1] {
+		fmt.Println("✅ Keys found by their original IDs")
+	} else {
+		fmt.Println("❌ Keys not found by their original IDs")
+	}
+	if _, err := kc.CreateKeyWithID(ids[0]); err != nil {
+		fmt.Printf("✅ Duplicate ID rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Duplicate ID accepted")
+	}
+
+	kc.SetDerivedKeyIDs(true)
+	node := kc.CreateKey()
+	if node.KeyID == keychain.DeriveKeyID(node.KeyBytes, node.Timestamp) && len(node.KeyID) == 16 {
+		fmt.Printf("✅ Derived key ID matches hash of key bytes and timestamp: %s\n", node.KeyID)
+	} else {
+		fmt.Printf("❌ Derived key ID %s does not match\n", node.KeyID)
+	}
+}
+
+// TestWrappedKeys tests saving data keys wrapped under a master key
+func TestWrappedKeys(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: WRAPPED KEYS")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	cvFile := filepath.Join(dir, "cv.json")
+	keysFile := filepath.Join(dir, "keys_wrapped.json")
+
+	kek := cryptoutils.GenerateRandomBytes(32)
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	cv.SaveEncryptedCV(cvFile)
+	if err := cv.SaveKeysWrapped(keysFile, kek); err != nil {
+		fmt.Printf("❌ Failed to save wrapped keys: %v\n", err)
+		return
+	}
+
+	var manifest models.KeyManifest
+	fileio.LoadJSON(keysFile, &manifest)
+	rawKeys := 0
+	for _, key := range manifest.Keys {
+		if key.Key != "" || key.Wrapped == nil {
+			rawKeys++
+		}
+	}
+	if rawKeys == 0 && len(manifest.Keys) > 0 {
+		fmt.Printf("✅ All %d keys stored wrapped, none in the clear\n", len(manifest.Keys))
+	} else {
+		fmt.Printf("❌ %d keys stored unwrapped\n", rawKeys)
+	}
+
+	restored := securecv.NewSecureCV()
+	restored.LoadEncryptedCV(cvFile)
+	if err := restored.LoadKeysWrapped(keysFile, kek); err != nil {
+		fmt.Printf("❌ Failed to load wrapped keys: %v\n", err)
+	} else {
+		original, _ := cv.GetField("email")
+		email, err := restored.GetField("email")
+		if err == nil && email == original {
+			fmt.Println("✅ Fields decrypt after loading with the correct master key")
+		} else {
+			fmt.Printf("❌ Field did not decrypt after loading wrapped keys: %v\n", err)
+		}
+	}
+
+	wrong := securecv.NewSecureCV()
+	wrong.LoadEncryptedCV(cvFile)
+	if err := wrong.LoadKeysWrapped(keysFile, cryptoutils.GenerateRandomBytes(32)); err != nil {
+		fmt.Printf("✅ Wrong master key rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Wrong master key accepted")
+	}
+	if err := wrong.LoadKeys(keysFile); err != nil {
+		fmt.Printf("✅ LoadKeys refuses a wrapped manifest: %v\n", err)
+	} else {
+		fmt.Println("❌ LoadKeys accepted a wrapped manifest")
+	}
+}
+
+// TestReKeyMaster tests rewrapping data keys under a new master key
+func TestReKeyMaster(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: REKEY MASTER")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	cvFile := filepath.Join(dir, "cv.json")
+	keysFile := filepath.Join(dir, "keys_wrapped.json")
+
+	oldKEK := cryptoutils.GenerateRandomBytes(32)
+	newKEK := cryptoutils.GenerateRandomBytes(32)
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	cv.SaveEncryptedCV(cvFile)
+	cv.SaveKeysWrapped(keysFile, oldKEK)
+	cvBefore, _ := os.ReadFile(cvFile)
+
+	if err := cv.ReKeyMaster(cryptoutils.GenerateRandomBytes(32), newKEK); err != nil {
+		fmt.Printf("✅ Wrong old master key rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Wrong old master key accepted")
+	}
+
+	if err := cv.ReKeyMaster(oldKEK, newKEK); err != nil {
+		fmt.Printf("❌ ReKeyMaster failed: %v\n", err)
+		return
+	}
+	fmt.Println("✅ Data keys rewrapped under the new master key")
+
+	cvAfter, _ := os.ReadFile(cvFile)
+	if bytes.Equal(cvBefore, cvAfter) {
+		fmt.Println("✅ Field ciphertexts untouched")
+	} else {
+		fmt.Println("❌ Field ciphertexts changed")
+	}
+
+	restored := securecv.NewSecureCV()
+	restored.LoadEncryptedCV(cvFile)
+	if err := restored.LoadKeysWrapped(keysFile, newKEK); err != nil {
+		fmt.Printf("❌ New master key does not unwrap: %v\n", err)
+	} else {
+		original, _ := cv.GetField("email")
+		email, err := restored.GetField("email")
+		if err == nil && email == original {
+			fmt.Println("✅ Fields decrypt with keys unwrapped by the new master key")
+		} else {
+			fmt.Printf("❌ Field did not decrypt after rekey: %v\n", err)
+		}
+	}
+
+	stale := securecv.NewSecureCV()
+	if err := stale.LoadKeysWrapped(keysFile, oldKEK); err != nil {
+		fmt.Println("✅ Old master key no longer unwraps")
+	} else {
+		fmt.Println("❌ Old master key still unwraps")
+	}
+}
+
+// TestSealedCV tests whole-file encryption of the saved CV
+func TestSealedCV(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: SEALED CV")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	cvFile := filepath.Join(dir, "cv.sealed")
+	keysFile := filepath.Join(dir, "keys.json")
+
+	fileKey := cryptoutils.GenerateRandomBytes(32)
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	cv.SaveKeys(keysFile)
+	if err := cv.SaveEncryptedCVSealed(cvFile, fileKey); err != nil {
+		fmt.Printf("❌ Failed to save sealed CV: %v\n", err)
+		return
+	}
+
+	onDisk, _ := os.ReadFile(cvFile)
+	if !json.Valid(onDisk) && !bytes.Contains(onDisk, []byte("email")) {
+		fmt.Println("✅ Sealed file is not JSON and hides field names")
+	} else {
+		fmt.Println("❌ Sealed file exposes its structure")
+	}
+
+	restored := securecv.NewSecureCV()
+	if err := restored.LoadEncryptedCVSealed(cvFile, fileKey); err != nil {
+		fmt.Printf("❌ Failed to load sealed CV: %v\n", err)
+		return
+	}
+	restored.LoadKeys(keysFile)
+	original, _ := cv.GetField("email")
+	email, err := restored.GetField("email")
+	if err == nil && email == original && len(restored.Topology().Fields) == len(cvData) {
+		fmt.Printf("✅ Sealed load restores all %d fields\n", len(cvData))
+	} else {
+		fmt.Printf("❌ Sealed load did not restore the CV: %v\n", err)
+	}
+
+	if err := securecv.NewSecureCV().LoadEncryptedCVSealed(cvFile, cryptoutils.GenerateRandomBytes(32)); err != nil {
+		fmt.Println("✅ Wrong file key rejected")
+	} else {
+		fmt.Println("❌ Wrong file key accepted")
+	}
+}
+
+// TestListFilesRecursive tests listing matching files in nested folders
+func TestListFilesRecursive() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: LIST FILES RECURSIVE")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	files := []string{
+		"top.json",
+		"notes.txt",
+		filepath.Join("team", "alice.json"),
+		filepath.Join("team", "alice.txt"),
+		filepath.Join("team", "archive", "2023", "bob.json"),
+		filepath.Join("empty", "nested", "readme.md"),
+	}
+	for _, name := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("{}"), 0644)
+	}
+
+	matches, err := fileio.ListFilesRecursive(dir, ".json")
+	if err != nil {
+		fmt.Printf("❌ ListFilesRecursive failed: %v\n", err)
+		return
+	}
+	expected := []string{
+		filepath.Join("team", "alice.json"),
+		filepath.Join("team", "archive", "2023", "bob.json"),
+		"top.json",
+	}
+	sort.Strings(matches)
+	sort.Strings(expected)
+	if reflect.DeepEqual(matches, expected) {
+		fmt.Printf("✅ Found nested matches with relative paths: %v\n", matches)
+	} else {
+		fmt.Printf("❌ Expected %v, got %v\n", expected, matches)
+	}
+
+	all, _ := fileio.ListFilesRecursive(dir, "")
+	if len(all) == len(files) {
+		fmt.Printf("✅ Empty extension lists all %d files\n", len(all))
+	} else {
+		fmt.Printf("❌ Empty extension listed %d of %d files\n", len(all), len(files))
+	}
+
+	top, _ := fileio.ListFiles(dir, ".json")
+	if len(top) == 1 && top[0] == "top.json" {
+		fmt.Println("✅ ListFiles still reads only the top level")
+	} else {
+		fmt.Printf("❌ ListFiles returned %v\n", top)
+	}
+}
+
+// TestListFilesExtension tests that extensions match only at a dot boundary
+func TestListFilesExtension() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: LIST FILES EXTENSION")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"report.json", "reportjson", "data.geojson", "notes.txt"} {
+		os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644)
+	}
+
+	for _, extension := range []string{"json", ".json"} {
+		matches, err := fileio.ListFiles(dir, extension)
+		if err == nil && len(matches) == 1 && matches[0] == "report.json" {
+			fmt.Printf("✅ Extension %q matches only report.json\n", extension)
+		} else {
+			fmt.Printf("❌ Extension %q matched %v\n", extension, matches)
+		}
+	}
+
+	geo, _ := fileio.ListFiles(dir, "geojson")
+	if len(geo) == 1 && geo[0] == "data.geojson" {
+		fmt.Println("✅ Extension \"geojson\" matches data.geojson")
+	} else {
+		fmt.Printf("❌ Extension \"geojson\" matched %v\n", geo)
+	}
+}
+
+// TestEncryptDirectory tests batch encryption of a folder of CVs
+func TestEncryptDirectory(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: ENCRYPT DIRECTORY")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	inDir := filepath.Join(dir, "in")
+	outDir := filepath.Join(dir, "out")
+
+	fileio.EnsureDirectory(inDir)
+	fileio.SaveJSON(filepath.Join(inDir, "alice.json"), cvData)
+	fileio.SaveJSON(filepath.Join(inDir, "bob.json"), map[string]interface{}{"name": "Bob", "email": "bob@example.com"})
+	os.WriteFile(filepath.Join(inDir, "broken.json"), []byte("{not json"), 0644)
+
+	processed, err := securecv.EncryptDirectory(inDir, outDir, "multi")
+	sort.Strings(processed)
+	if reflect.DeepEqual(processed, []string{"alice.json", "bob.json"}) {
+		fmt.Printf("✅ Processed %v\n", processed)
+	} else {
+		fmt.Printf("❌ Processed %v\n", processed)
+	}
+	if err != nil && strings.Contains(err.Error(), "broken.json") {
+		fmt.Println("✅ Bad file reported without aborting the batch")
+	} else {
+		fmt.Printf("❌ Expected an error naming broken.json, got %v\n", err)
+	}
+
+	for _, name := range []string{"alice", "bob"} {
+		cv, err := securecv.LoadPair(filepath.Join(outDir, name+".enc.json"), filepath.Join(outDir, name+".keys.json"))
+		if err != nil {
+			fmt.Printf("❌ Outputs for %s do not load: %v\n", name, err)
+			continue
+		}
+		if _, err := cv.GetField("email"); err == nil {
+			fmt.Printf("✅ %s.enc.json and %s.keys.json decrypt\n", name, name)
+		} else {
+			fmt.Printf("❌ %s outputs do not decrypt: %v\n", name, err)
+		}
+	}
+}
+
+// TestPreviewLoad tests that a load preview matches the real key topology
+func TestPreviewLoad(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: PREVIEW LOAD")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	for _, mode := range []string{"single", "multi"} {
+		cv := securecv.NewSecureCV()
+		preview, err := cv.PreviewLoad(cvData, mode)
+		if err != nil {
+			fmt.Printf("❌ Preview failed in %s mode: %v\n", mode, err)
+			continue
+		}
+		if cv.KeyChain().Size() != 0 || len(cv.Topology().Fields) != 0 {
+			fmt.Printf("❌ Preview in %s mode created keys or fields\n", mode)
+		}
+
+		cv.LoadCV(cvData, mode)
+		manifest := cv.GetAllKeys()
+		if len(preview.Keys) == len(manifest.Keys) && len(preview.FieldMap) == len(manifest.FieldMap) {
+			fmt.Printf("✅ %s mode preview: %d keys for %d fields, matching the real load\n", mode, len(preview.Keys), len(preview.FieldMap))
+		} else {
+			fmt.Printf("❌ %s mode preview has %d keys/%d fields, real load %d/%d\n", mode, len(preview.Keys), len(preview.FieldMap), len(manifest.Keys), len(manifest.FieldMap))
+		}
+		for _, key := range preview.Keys {
+			if key.Key != "" {
+				fmt.Println("❌ Preview contains key material")
+				break
+			}
+		}
+	}
+
+	if _, err := securecv.NewSecureCV().PreviewLoad(cvData, "double"); err != nil {
+		fmt.Printf("✅ Unknown mode rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Unknown mode accepted")
+	}
+}
+
+// TestKeySize tests loading and rotating with AES-128 keys
+func TestKeySize(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: KEY SIZE")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	if err := cv.SetKeySize(100); err != nil {
+		fmt.Printf("✅ Invalid key size rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Invalid key size accepted")
+	}
+	if err := cv.SetKeySize(128); err != nil {
+		fmt.Printf("❌ Failed to set key size: %v\n", err)
+		return
+	}
+	cv.LoadCV(cvData, "multi")
+	cv.RotateAllKeys()
+
+	wrongSize := 0
+	for _, node := range cv.KeyChain().GetAllKeys() {
+		if len(node.KeyBytes) != 16 {
+			wrongSize++
+		}
+	}
+	if wrongSize == 0 {
+		fmt.Println("✅ All loaded and rotated keys are 16 bytes")
+	} else {
+		fmt.Printf("❌ %d keys are not 16 bytes\n", wrongSize)
+	}
+
+	failures := 0
+	for field := range cvData {
+		if _, err := cv.GetField(field); err != nil {
+			failures++
+		}
+	}
+	if failures == 0 {
+		fmt.Printf("✅ All %d fields decrypt with AES-128 keys\n", len(cvData))
+	} else {
+		fmt.Printf("❌ %d fields failed to decrypt\n", failures)
+	}
+
+	if bits := cv.GetStats()["key_size_bits"]; bits == 128 {
+		fmt.Println("✅ GetStats reports key_size_bits 128")
+	} else {
+		fmt.Printf("❌ GetStats reports key_size_bits %v\n", bits)
+	}
+
+	node, err := keychain.NewKeyChain().CreateKeyWithSize(192)
+	if err == nil && len(node.KeyBytes) == 24 {
+		fmt.Println("✅ CreateKeyWithSize(192) creates a 24-byte key")
+	} else {
+		fmt.Printf("❌ CreateKeyWithSize(192) failed: %v\n", err)
+	}
+}
+
+// TestExportKeysJWKS tests JWK Set export of active keys
+func TestExportKeysJWKS(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: EXPORT KEYS JWKS")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	cv.RevokeFieldKey("email")
+
+	var buf bytes.Buffer
+	if err := cv.ExportKeysJWKS(&buf); err != nil {
+		fmt.Printf("❌ Failed to export JWKS: %v\n", err)
+		return
+	}
+
+	var set struct {
+		Keys []cryptoutils.JWK `json:"keys"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &set); err != nil {
+		fmt.Printf("❌ JWKS is not valid JSON: %v\n", err)
+		return
+	}
+	if len(set.Keys) == len(cvData)-1 {
+		fmt.Printf("✅ JWKS holds %d active keys, revoked key skipped\n", len(set.Keys))
+	} else {
+		fmt.Printf("❌ JWKS holds %d keys, expected %d\n", len(set.Keys), len(cvData)-1)
+	}
+
+	mismatches := 0
+	for _, jwk := range set.Keys {
+		key, err := base64.RawURLEncoding.DecodeString(jwk.K)
+		node := cv.KeyChain().GetNode(jwk.Kid)
+		if err != nil || node == nil || !bytes.Equal(key, node.KeyBytes) || jwk.Kty != "oct" || jwk.Alg != "A256GCM" {
+			mismatches++
+		}
+	}
+	if mismatches == 0 {
+		fmt.Println("✅ Each JWK is oct/A256GCM and k decodes (base64url) to the original key bytes")
+	} else {
+		fmt.Printf("❌ %d JWKs do not match their keys\n", mismatches)
+	}
+
+	jwk, _ := cryptoutils.ToJWK("short", cryptoutils.GenerateRandomBytes(16))
+	if strings.Contains(string(jwk), `"alg":"A128GCM"`) && !strings.ContainsAny(string(jwk), "=+/") {
+		fmt.Printf("✅ 128-bit key encodes as A128GCM without padding: %s\n", jwk)
+	} else {
+		fmt.Printf("❌ Unexpected JWK for a 128-bit key: %s\n", jwk)
+	}
+}
+
+// TestShortKeyIDs tests that short key IDs display without panicking
+func TestShortKeyIDs() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: SHORT KEY IDS")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	if models.ShortID("abcdef", 4) == "abcd" && models.ShortID("ab", 4) == "ab" {
+		fmt.Println("✅ ShortID truncates long IDs and keeps short ones whole")
+	} else {
+		fmt.Println("❌ ShortID returned unexpected values")
+	}
+
+	displayed := func() (ok bool) {
+		defer func() {
+			if r := recover(); r != nil {
+				fmt.Printf("❌ Display panicked: %v\n", r)
+				ok = false
+			}
+		}()
+		kc := keychain.NewKeyChain()
+		node, err := kc.CreateKeyWithID("abcd")
+		if err != nil {
+			fmt.Printf("❌ Failed to create key: %v\n", err)
+			return false
+		}
+		node.Display(1, true)
+		kc.Display()
+		return true
+	}()
+	if displayed {
+		fmt.Println("✅ 4-character key ID displayed without panicking")
+	}
+}
+
+// TestFindFields tests glob search over field names
+func TestFindFields() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: FIND FIELDS")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(map[string]interface{}{
+		"project_1": "Field cipher",
+		"project_2": "Key rotation",
+		"email":     "user@example.com",
+	}, "multi")
+
+	projects := cv.FindFields("project_*")
+	if reflect.DeepEqual(projects, []string{"project_1", "project_2"}) {
+		fmt.Printf("✅ project_* matches %v\n", projects)
+	} else {
+		fmt.Printf("❌ project_* matched %v\n", projects)
+	}
+
+	if all := cv.FindFields("*"); len(all) == 3 {
+		fmt.Println("✅ * matches all fields")
+	} else {
+		fmt.Printf("❌ * matched %v\n", all)
+	}
+	if none := cv.FindFields("[project"); len(none) == 0 {
+		fmt.Println("✅ Malformed pattern matches nothing")
+	} else {
+		fmt.Printf("❌ Malformed pattern matched %v\n", none)
+	}
+}
+
+// TestValidate tests detection of drift between field maps and keys
+func TestValidate(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: VALIDATE")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	cvFile := filepath.Join(dir, "cv.json")
+	keysFile := filepath.Join(dir, "keys.json")
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	cv.SaveKeys(keysFile)
+	if err := cv.Validate(); err == nil {
+		fmt.Println("✅ Freshly loaded CV validates")
+	} else {
+		fmt.Printf("❌ Freshly loaded CV failed validation: %v\n", err)
+	}
+
+	// Drop email from the field key map on disk
+	var data models.EncryptedCV
+	cv.SaveEncryptedCV(cvFile)
+	fileio.LoadJSON(cvFile, &data)
+	delete(data.FieldKeyMap, "email")
+	fileio.SaveJSON(cvFile, &data)
+
+	drifted := securecv.NewSecureCV()
+	drifted.LoadEncryptedCV(cvFile)
+	drifted.LoadKeys(keysFile)
+	if err := drifted.Validate(); err != nil && strings.Contains(err.Error(), "'email' has ciphertext but no key mapping") {
+		fmt.Printf("✅ Validate names the unmapped field: %v\n", err)
+	} else {
+		fmt.Printf("❌ Validate did not report email: %v\n", err)
+	}
+
+	strict := securecv.NewSecureCV()
+	strict.SetValidateOnLoad(true)
+	if err := strict.LoadEncryptedCV(cvFile); err != nil && strings.Contains(err.Error(), "'email'") {
+		fmt.Println("✅ Validate on load rejects the drifted file")
+	} else {
+		fmt.Printf("❌ Validate on load accepted the drifted file: %v\n", err)
+	}
+
+	noKeys := securecv.NewSecureCV()
+	cv.SaveEncryptedCV(cvFile)
+	noKeys.LoadEncryptedCV(cvFile)
+	if err := noKeys.Validate(); err != nil && strings.Contains(err.Error(), "not in the key chain") {
+		fmt.Println("✅ Validate reports key IDs missing from the key chain")
+	} else {
+		fmt.Printf("❌ Validate did not report missing keys: %v\n", err)
+	}
+}
+
+// TestConstantTimeKeyEqual tests timing-safe key comparison
+func TestConstantTimeKeyEqual() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: CONSTANT TIME KEY EQUAL")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	key := cryptoutils.GenerateRandomBytes(32)
+	same := append([]byte{}, key...)
+	flipped := append([]byte{}, key...)
+	flipped[31] ^= 1
+
+	cases := []struct {
+		name     string
+		a, b     []byte
+		expected bool
+	}{
+		{"equal keys", key, same, true},
+		{"unequal keys of the same length", key, flipped, false},
+		{"keys of different lengths", key, key[:16], false},
+		{"empty inputs", []byte{}, nil, true},
+	}
+	for _, tc := range cases {
+		if cryptoutils.ConstantTimeKeyEqual(tc.a, tc.b) == tc.expected {
+			fmt.Printf("✅ %s: %v\n", tc.name, tc.expected)
+		} else {
+			fmt.Printf("❌ %s: expected %v\n", tc.name, tc.expected)
+		}
+	}
+}
+
+// TestExportFull tests a full key chain export and import round trip
+func TestExportFull(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: EXPORT FULL")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	cv.RotateFieldKey("email")
+	cv.RevokeFieldKey("phone")
+	original := cv.KeyChain()
+
+	exported, err := original.ExportFull()
+	if err != nil {
+		fmt.Printf("❌ ExportFull failed: %v\n", err)
+		return
+	}
+	data, _ := json.Marshal(exported)
+	var manifest models.KeyManifest
+	json.Unmarshal(data, &manifest)
+
+	imported := keychain.NewKeyChain()
+	if err := imported.ImportFull(&manifest); err != nil {
+		fmt.Printf("❌ ImportFull failed: %v\n", err)
+		return
+	}
+
+	reexported, _ := imported.ExportFull()
+	if reflect.DeepEqual(exported, reexported) {
+		fmt.Printf("✅ %d keys round trip with identical order, bytes, timestamps, revocations and fields\n", len(exported.Order))
+	} else {
+		fmt.Println("❌ Imported chain differs from the original")
+	}
+	if imported.Size() == original.Size() && imported.GetCurrentKey().KeyID == original.GetCurrentKey().KeyID {
+		fmt.Println("✅ Size and current key restored")
+	} else {
+		fmt.Println("❌ Size or current key differs")
+	}
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	cvFile := filepath.Join(dir, "cv.json")
+
+	restored := securecv.NewSecureCVWithKeyChain(imported)
+	cv.SaveEncryptedCV(cvFile)
+	restored.LoadEncryptedCV(cvFile)
+	email, err := restored.GetField("email")
+	if err == nil && email == cvData["email"] {
+		fmt.Println("✅ Imported chain decrypts the rotated field")
+	} else {
+		fmt.Printf("❌ Imported chain failed to decrypt: %v\n", err)
+	}
+
+	if err := imported.ImportFull(&manifest); err != nil {
+		fmt.Printf("✅ ImportFull into a populated chain rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ ImportFull into a populated chain accepted")
+	}
+
+	if metadata := original.ExportKeyChain(); len(metadata.Keys) > 0 && metadata.Keys[original.GetCurrentKey().KeyID].Key == "" {
+		fmt.Println("✅ ExportKeyChain stays metadata-only")
+	} else {
+		fmt.Println("❌ ExportKeyChain exposed key bytes")
+	}
+}
+
+// TestImportKeyMetadata tests that key timestamps and revocations survive
+// export and import
+func TestImportKeyMetadata(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: IMPORT KEY METADATA")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	keysFile := filepath.Join(dir, "keys.json")
+	fullFile := filepath.Join(dir, "keys_full.json")
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	twoHoursAgo := time.Now().Add(-2 * time.Hour).Unix()
+	cv.KeyChain().GetNode(cv.GetAllKeys().FieldMap["email"]).Timestamp = twoHoursAgo
+	phoneKey := cv.GetAllKeys().FieldMap["phone"]
+	cv.RevokeKey(phoneKey)
+	cv.KeyChain().GetNode(phoneKey).Timestamp = twoHoursAgo
+
+	// Active key timestamps survive SaveKeys / LoadKeys
+	cv.SaveKeys(keysFile)
+	reloaded := securecv.NewSecureCV()
+	reloaded.LoadKeys(keysFile)
+	emailKey := reloaded.KeyChain().GetNode(cv.GetAllKeys().FieldMap["email"])
+	if emailKey != nil && emailKey.Timestamp == twoHoursAgo {
+		fmt.Println("✅ Key timestamp restored by LoadKeys")
+	} else {
+		fmt.Println("❌ Key timestamp lost by LoadKeys")
+	}
+	if metadata := cv.KeyChain().ExportKeyChain(); metadata.Keys[emailKey.KeyID].Timestamp == twoHoursAgo {
+		fmt.Println("✅ ExportKeyChain carries key timestamps")
+	} else {
+		fmt.Println("❌ ExportKeyChain dropped key timestamps")
+	}
+
+	// A revoked key with an old timestamp is purged after import
+	full, _ := cv.KeyChain().ExportFull()
+	fileio.SaveJSON(fullFile, full)
+	imported := securecv.NewSecureCV()
+	if err := imported.LoadKeys(fullFile); err != nil {
+		fmt.Printf("❌ Failed to load full export: %v\n", err)
+		return
+	}
+	revoked := imported.KeyChain().GetNode(phoneKey)
+	if revoked != nil && revoked.Revoked && revoked.Timestamp == twoHoursAgo {
+		fmt.Println("✅ Revoked flag and timestamp restored on import")
+	} else {
+		fmt.Println("❌ Revoked flag or timestamp lost on import")
+	}
+	removed := imported.KeyChain().CleanupRevokedKeys(time.Hour)
+	if removed == 1 && imported.KeyChain().GetNode(phoneKey) == nil {
+		fmt.Println("✅ CleanupRevokedKeys purged the old revoked key using its restored timestamp")
+	} else {
+		fmt.Printf("❌ CleanupRevokedKeys removed %d keys\n", removed)
+	}
+}
+
+// TestMerge tests combining two encrypted profiles
+func TestMerge(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: MERGE")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	public := map[string]interface{}{}
+	private := map[string]interface{}{}
+	for field, value := range cvData {
+		if field == "email" || field == "phone" {
+			private[field] = value
+		} else {
+			public[field] = value
+		}
+	}
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(public, "multi")
+	currentBefore := cv.KeyChain().GetCurrentKey().KeyID
+	privateCV := securecv.NewSecureCV()
+	privateCV.SetAAD([]byte("private-section"))
+	privateCV.LoadCV(private, "single")
+
+	if err := cv.Merge(privateCV); err != nil {
+		fmt.Printf("❌ Merge failed: %v\n", err)
+		return
+	}
+
+	failures := 0
+	for field, value := range cvData {
+		decrypted, err := cv.GetField(field)
+		if err != nil || fmt.Sprint(decrypted) != fmt.Sprint(value) {
+			failures++
+		}
+	}
+	if failures == 0 {
+		fmt.Printf("✅ All %d fields from both CVs decrypt after merge\n", len(cvData))
+	} else {
+		fmt.Printf("❌ %d fields failed to decrypt after merge\n", failures)
+	}
+	if cv.KeyChain().Size() == len(public)+1 && cv.KeyChain().GetCurrentKey().KeyID == currentBefore {
+		fmt.Println("✅ Merged key imported once, current key unchanged")
+	} else {
+		fmt.Printf("❌ Unexpected key chain after merge: %d keys\n", cv.KeyChain().Size())
+	}
+
+	colliding := securecv.NewSecureCV()
+	colliding.LoadCV(map[string]interface{}{"email": "other@example.com", "website": "example.com"}, "multi")
+	sizeBefore := cv.KeyChain().Size()
+	if err := cv.Merge(colliding); err != nil && strings.Contains(err.Error(), "email") {
+		fmt.Printf("✅ Field collision rejected: %v\n", err)
+	} else {
+		fmt.Printf("❌ Field collision not rejected: %v\n", err)
+	}
+	if _, err := cv.GetField("website"); err != nil && cv.KeyChain().Size() == sizeBefore {
+		fmt.Println("✅ Failed merge left the CV unchanged")
+	} else {
+		fmt.Println("❌ Failed merge partially applied")
+	}
+
+	// A store failure after the first field undoes the fields and keys already merged
+	extra := securecv.NewSecureCV()
+	extra.LoadCV(map[string]interface{}{"github": "violet-k", "website": "example.com"}, "multi")
+	sizeBefore = cv.KeyChain().Size()
+	cv.EnableWALStore(&failingWAL{remaining: 2})
+	err := cv.Merge(extra)
+	cv.DisableWAL()
+	_, githubErr := cv.GetField("github")
+	if err != nil && githubErr != nil && cv.KeyChain().Size() == sizeBefore && cv.KeyChain().GetCurrentKey().KeyID == currentBefore {
+		fmt.Printf("✅ Merge failing partway rolled back: %v\n", err)
+	} else {
+		fmt.Printf("❌ Merge failing partway left changes: err %v, keys %d -> %d\n", err, sizeBefore, cv.KeyChain().Size())
+	}
+}
+
+// TestAutoRotateExpired tests rotating only fields with old keys
+func TestAutoRotateExpired(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: AUTO ROTATE EXPIRED")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	fieldMap := cv.GetAllKeys().FieldMap
+	old := time.Now().Add(-48 * time.Hour).Unix()
+	for _, field := range []string{"email", "phone", "name"} {
+		cv.KeyChain().GetNode(fieldMap[field]).Timestamp = old
+	}
+	cv.RevokeKey(fieldMap["name"])
+	cv.KeyChain().GetNode(fieldMap["name"]).Timestamp = old
+
+	rotated, err := cv.AutoRotateExpired(24 * time.Hour)
+	if err == nil && reflect.DeepEqual(rotated, []string{"email", "phone"}) {
+		fmt.Printf("✅ Only expired fields rotated: %v\n", rotated)
+	} else {
+		fmt.Printf("❌ Rotated %v (err: %v)\n", rotated, err)
+	}
+
+	after := cv.GetAllKeys().FieldMap
+	unchanged := 0
+	for field, keyID := range fieldMap {
+		if after[field] == keyID {
+			unchanged++
+		}
+	}
+	if unchanged == len(fieldMap)-2 {
+		fmt.Println("✅ Fresh and revoked fields kept their keys")
+	} else {
+		fmt.Printf("❌ %d of %d other fields kept their keys\n", unchanged, len(fieldMap)-2)
+	}
+
+	email, err := cv.GetField("email")
+	if err == nil && email == cvData["email"] {
+		fmt.Println("✅ Rotated field still decrypts")
+	} else {
+		fmt.Printf("❌ Rotated field failed to decrypt: %v\n", err)
+	}
+
+	if again, _ := cv.AutoRotateExpired(24 * time.Hour); len(again) == 0 {
+		fmt.Println("✅ Second pass finds nothing to rotate")
+	} else {
+		fmt.Printf("❌ Second pass rotated %v\n", again)
+	}
+}
+
+// TestFieldMetadata tests authenticated labels on stored records
+func TestFieldMetadata(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: FIELD METADATA")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	cvFile := filepath.Join(dir, "cv.json")
+	keysFile := filepath.Join(dir, "keys.json")
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	if err := cv.SetFieldMetadata("email", map[string]string{"classification": "confidential", "version": "2"}); err != nil {
+		fmt.Printf("❌ Failed to set metadata: %v\n", err)
+		return
+	}
+	cv.RotateFieldKey("email")
+
+	metadata, err := cv.GetFieldMetadata("email")
+	email, _ := cv.GetField("email")
+	if err == nil && metadata["classification"] == "confidential" && email == cvData["email"] {
+		fmt.Println("✅ Label survives rotation and the field decrypts")
+	} else {
+		fmt.Printf("❌ Label or value lost: %v %v\n", metadata, err)
+	}
+
+	cv.SaveEncryptedCV(cvFile)
+	cv.SaveKeys(keysFile)
+	tamper := func(edit func(record *models.EncryptedData)) error {
+		var data models.EncryptedCV
+		fileio.LoadJSON(cvFile, &data)
+		edit(data.EncryptedData["email"])
+		tamperedFile := filepath.Join(dir, "tampered.json")
+		fileio.SaveJSON(tamperedFile, &data)
+
+		tampered := securecv.NewSecureCV()
+		tampered.LoadEncryptedCV(tamperedFile)
+		tampered.LoadKeys(keysFile)
+		_, err := tampered.GetField("email")
+		return err
+	}
+
+	if err := tamper(func(record *models.EncryptedData) { record.Metadata["classification"] = "public" }); err != nil {
+		fmt.Println("✅ Changing the stored label breaks decryption")
+	} else {
+		fmt.Println("❌ Changed label went undetected")
+	}
+	if err := tamper(func(record *models.EncryptedData) { record.Metadata = nil }); err != nil {
+		fmt.Println("✅ Stripping the stored labels breaks decryption")
+	} else {
+		fmt.Println("❌ Stripped labels went undetected")
+	}
+	if err := tamper(func(record *models.EncryptedData) {}); err == nil {
+		fmt.Println("✅ Untouched record still decrypts after reload")
+	} else {
+		fmt.Printf("❌ Untouched record failed after reload: %v\n", err)
+	}
+}
+
+// TestExportRedacted tests exporting the CV's shape without content
+func TestExportRedacted(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: EXPORT REDACTED")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	redacted := cv.ExportRedacted()
+
+	missing := 0
+	for field := range cvData {
+		if _, exists := redacted[field]; !exists {
+			missing++
+		}
+	}
+	if missing == 0 && len(redacted) == len(cvData) {
+		fmt.Printf("✅ All %d field names listed\n", len(redacted))
+	} else {
+		fmt.Printf("❌ %d fields missing from the redacted export\n", missing)
+	}
+
+	email, _ := redacted["email"].(map[string]interface{})
+	if email["type"] == "string" && email["ciphertext_length"].(int) > 0 {
+		fmt.Printf("✅ email described as %v\n", email)
+	} else {
+		fmt.Printf("❌ Unexpected description for email: %v\n", email)
+	}
+
+	exported, _ := json.Marshal(redacted)
+	manifest := cv.GetAllKeys()
+	leaks := 0
+	for field, keyID := range manifest.FieldMap {
+		record, _ := cv.ExportField(field)
+		var encrypted models.EncryptedData
+		encrypted.FromJSON(record["encrypted_data"].(string))
+		value, _ := cv.GetField(field)
+		text, isString := value.(string)
+		if bytes.Contains(exported, []byte(encrypted.Ciphertext)) || bytes.Contains(exported, []byte(manifest.Keys[keyID].Key)) ||
+			bytes.Contains(exported, []byte(keyID)) || (isString && bytes.Contains(exported, []byte(text))) {
+			leaks++
+		}
+	}
+	if leaks == 0 {
+		fmt.Println("✅ No ciphertext, key material, key IDs or values in the export")
+	} else {
+		fmt.Printf("❌ %d fields leaked content into the export\n", leaks)
+	}
+}
+
+// TestForEachField tests iterating all fields under one lock
+func TestForEachField(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: FOR EACH FIELD")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+
+	// Rotate concurrently; iteration must still see a consistent snapshot
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			cv.RotateFieldKey("email")
+		}
+	}()
+
+	collected := make(map[string]interface{})
+	var order []string
+	err := cv.ForEachField(func(field string, value interface{}) error {
+		collected[field] = value
+		order = append(order, field)
+		return nil
+	})
+	<-done
+
+	mismatches := 0
+	for field := range cvData {
+		direct, _ := cv.GetField(field)
+		if !reflect.DeepEqual(collected[field], direct) {
+			mismatches++
+		}
+	}
+	if err == nil && mismatches == 0 && len(collected) == len(cvData) && sort.StringsAreSorted(order) {
+		fmt.Printf("✅ Iterated %d fields in name order, matching GetField\n", len(collected))
+	} else {
+		fmt.Printf("❌ Iteration mismatched GetField on %d fields (err: %v)\n", mismatches, err)
+	}
+
+	visited := 0
+	stop := fmt.Errorf("stop")
+	err = cv.ForEachField(func(field string, value interface{}) error {
+		visited++
+		if visited == 3 {
+			return stop
+		}
+		return nil
+	})
+	if err == stop && visited == 3 {
+		fmt.Println("✅ Iteration stops at the first callback error")
+	} else {
+		fmt.Printf("❌ Iteration visited %d fields, err %v\n", visited, err)
+	}
+}
+
+// TestDecryptCache tests the LRU cache of decrypted fields
+func TestDecryptCache(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: DECRYPT CACHE")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	cv.EnableDecryptCache(2)
+
+	cv.GetField("email")
+	email, _ := cv.GetField("email")
+	stats := cv.GetStats()
+	if stats["cache_hits"] == 1 && stats["cache_misses"] == 1 && email == cvData["email"] {
+		fmt.Println("✅ Second read is a cache hit")
+	} else {
+		fmt.Printf("❌ Expected 1 hit and 1 miss, got %v hits, %v misses\n", stats["cache_hits"], stats["cache_misses"])
+	}
+
+	cv.RotateFieldKey("email")
+	email, err := cv.GetField("email")
+	stats = cv.GetStats()
+	if err == nil && email == cvData["email"] && stats["cache_hits"] == 1 && stats["cache_misses"] == 2 {
+		fmt.Println("✅ Read after rotation misses and returns the correct value")
+	} else {
+		fmt.Printf("❌ Read after rotation: %v (err: %v, stats %v/%v)\n", email, err, stats["cache_hits"], stats["cache_misses"])
+	}
+
+	cv.SetAllowOverwrite(true)
+	cv.SetField("email", "new@example.com", "single")
+	if email, _ := cv.GetField("email"); email == "new@example.com" {
+		fmt.Println("✅ Overwritten field is not served from the cache")
+	} else {
+		fmt.Printf("❌ Stale value served after SetField: %v\n", email)
+	}
+
+	cv.RevokeFieldKey("email")
+	if _, err := cv.GetField("email"); err != nil {
+		fmt.Println("✅ Revoked field is not served from the cache")
+	} else {
+		fmt.Println("❌ Revoked field served from the cache")
+	}
+
+	cv.GetField("name")
+	cv.GetField("phone")
+	cv.GetField("skills")
+	if stats = cv.GetStats(); stats["cache_entries"] == 2 {
+		fmt.Println("✅ Least recently used entry evicted at capacity")
+	} else {
+		fmt.Printf("❌ Cache holds %v entries, capacity 2\n", stats["cache_entries"])
+	}
+
+	cv.EnableDecryptCache(0)
+	if _, enabled := cv.GetStats()["cache_hits"]; !enabled {
+		fmt.Println("✅ Capacity 0 disables the cache")
+	} else {
+		fmt.Println("❌ Cache still enabled")
+	}
+}
+
+// TestNullAndEmptyValues tests that nil and "" round-trip exactly
+func TestNullAndEmptyValues() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: NULL AND EMPTY VALUES")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	for _, mode := range []string{"single", "multi"} {
+		cv := securecv.NewSecureCV()
+		if err := cv.LoadCV(map[string]interface{}{"middle_name": nil, "nickname": ""}, mode); err != nil {
+			fmt.Printf("❌ Failed to load nil and empty values: %v\n", err)
+			continue
+		}
+
+		value, err := cv.GetField("middle_name")
+		if err == nil && value == nil {
+			fmt.Printf("✅ %s mode: nil decrypts to nil\n", mode)
+		} else {
+			fmt.Printf("❌ %s mode: nil decrypted to %#v (err: %v)\n", mode, value, err)
+		}
+
+		value, err = cv.GetField("nickname")
+		if text, ok := value.(string); err == nil && ok && text == "" {
+			fmt.Printf("✅ %s mode: empty string decrypts to \"\"\n", mode)
+		} else {
+			fmt.Printf("❌ %s mode: empty string decrypted to %#v (err: %v)\n", mode, value, err)
+		}
+	}
+
+	key := cryptoutils.GenerateRandomBytes(32)
+	encrypted, _ := cryptoutils.EncryptData(nil, key, nil)
+	if encrypted.Type == "null" {
+		fmt.Println("✅ nil is tagged with type \"null\"")
+	} else {
+		fmt.Printf("❌ nil tagged as %q\n", encrypted.Type)
+	}
+}
+
+// TestFieldClassification tests clearance checks on classified fields
+func TestFieldClassification(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: FIELD CLASSIFICATION")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	cvFile := filepath.Join(dir, "cv.json")
+	keysFile := filepath.Join(dir, "keys.json")
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	cv.SetFieldClassification("phone", securecv.ClassSecret)
+	cv.SetFieldClassification("email", securecv.ClassInternal)
+	if err := cv.SetFieldClassification("email", "top-secret"); err != nil {
+		fmt.Printf("✅ Unknown classification rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Unknown classification accepted")
+	}
+
+	cases := []struct {
+		field, clearance string
+		allowed          bool
+	}{
+		{"name", securecv.ClassPublic, true},
+		{"email", securecv.ClassPublic, false},
+		{"email", securecv.ClassInternal, true},
+		{"phone", securecv.ClassConfidential, false},
+		{"phone", securecv.ClassSecret, true},
+	}
+	for _, tc := range cases {
+		value, err := cv.GetFieldWithPolicy(tc.field, tc.clearance)
+		var permErr *securecv.PermissionError
+		switch {
+		case tc.allowed && err == nil && value == cvData[tc.field]:
+			fmt.Printf("✅ %s clearance reads %s\n", tc.clearance, tc.field)
+		case !tc.allowed && errors.As(err, &permErr):
+			fmt.Printf("✅ %s clearance denied %s: %v\n", tc.clearance, tc.field, err)
+		default:
+			fmt.Printf("❌ %s clearance on %s: value %v, err %v\n", tc.clearance, tc.field, value, err)
+		}
+	}
+
+	cv.SaveEncryptedCV(cvFile)
+	cv.SaveKeys(keysFile)
+	restored, _ := securecv.LoadPair(cvFile, keysFile)
+	if restored != nil && restored.GetFieldClassification("phone") == securecv.ClassSecret && restored.GetFieldClassification("name") == securecv.ClassPublic {
+		fmt.Println("✅ Classifications saved with the encrypted CV")
+	} else {
+		fmt.Println("❌ Classifications lost on save and load")
+	}
+}
+
+// TestBundle tests exporting and importing a single-file bundle
+func TestBundle(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: BUNDLE")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	withKeys := filepath.Join(dir, "bundle_keys.json")
+	withoutKeys := filepath.Join(dir, "bundle.json")
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	if err := cv.ExportBundle(withKeys, true); err != nil {
+		fmt.Printf("❌ Failed to export bundle: %v\n", err)
+		return
+	}
+	cv.ExportBundle(withoutKeys, false)
+
+	imported, err := securecv.ImportBundle(withKeys)
+	if err != nil {
+		fmt.Printf("❌ Failed to import bundle: %v\n", err)
+		return
+	}
+	failures := 0
+	for field, value := range cvData {
+		decrypted, err := imported.GetField(field)
+		if err != nil || fmt.Sprint(decrypted) != fmt.Sprint(value) {
+			failures++
+		}
+	}
+	if failures == 0 {
+		fmt.Printf("✅ All %d fields decrypt from the imported bundle\n", len(cvData))
+	} else {
+		fmt.Printf("❌ %d fields failed to decrypt from the bundle\n", failures)
+	}
+
+	var bundle models.CVBundle
+	fileio.LoadJSON(withoutKeys, &bundle)
+	keyless, err := securecv.ImportBundle(withoutKeys)
+	if err == nil && bundle.Keys == nil && len(keyless.Topology().Fields) == len(cvData) {
+		if _, err := keyless.GetField("email"); err != nil {
+			fmt.Println("✅ Bundle without keys holds the structure but cannot decrypt")
+		} else {
+			fmt.Println("❌ Bundle without keys decrypted a field")
+		}
+	} else {
+		fmt.Printf("❌ Bundle without keys did not import as expected: %v\n", err)
+	}
+}
+
+// TestDerivedSubkeys tests per-field keys derived from a single root key
+func TestDerivedSubkeys(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: DERIVED SUBKEYS")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	root := cryptoutils.GenerateRandomBytes(32)
+	cv := securecv.NewSecureCV()
+	if err := cv.LoadCVDerived(cvData, root); err != nil {
+		fmt.Printf("❌ LoadCVDerived failed: %v\n", err)
+		return
+	}
+
+	emailKey := cryptoutils.DeriveSubkey(root, "field_cipher field email")
+	phoneKey := cryptoutils.DeriveSubkey(root, "field_cipher field phone")
+	if !bytes.Equal(emailKey, phoneKey) && !bytes.Equal(emailKey, root) {
+		fmt.Println("✅ Different fields derive different subkeys")
+	} else {
+		fmt.Println("❌ Derived subkeys collide")
+	}
+
+	exported, _ := cv.ExportFieldBundle("email")
+	if exported != nil {
+		if _, err := cryptoutils.DecryptData(exported.Encrypted, root, cryptoutils.FieldAAD("email", nil)); err != nil {
+			fmt.Println("✅ The root key alone does not decrypt a field's ciphertext")
+		} else {
+			fmt.Println("❌ Field was encrypted directly under the root key")
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	cvFile := filepath.Join(dir, "derived_cv.json")
+	keysFile := filepath.Join(dir, "derived_keys.json")
+	cv.SaveEncryptedCV(cvFile)
+	cv.SaveKeys(keysFile)
+
+	var manifest models.KeyManifest
+	fileio.LoadJSON(keysFile, &manifest)
+	onlyRoot := len(manifest.Keys) == 1
+	for _, key := range manifest.Keys {
+		onlyRoot = onlyRoot && key.Key == base64.StdEncoding.EncodeToString(root)
+	}
+	if onlyRoot {
+		fmt.Println("✅ Only the root key is persisted")
+	} else {
+		fmt.Printf("❌ Expected only the root key in the manifest, got %d keys\n", len(manifest.Keys))
+	}
+
+	loaded, err := securecv.LoadPair(cvFile, keysFile)
+	if err != nil {
+		fmt.Printf("❌ Failed to load derived CV: %v\n", err)
+		return
+	}
+	failures := 0
+	for field, value := range cvData {
+		decrypted, err := loaded.GetField(field)
+		if err != nil || fmt.Sprint(decrypted) != fmt.Sprint(value) {
+			failures++
+		}
+	}
+	if failures == 0 {
+		fmt.Printf("✅ All %d fields re-derive and decrypt after reload\n", len(cvData))
+	} else {
+		fmt.Printf("❌ %d fields failed to decrypt after reload\n", failures)
+	}
+
+	if _, err := loaded.RotateFieldKey("email"); err == nil {
+		if value, err := loaded.GetField("email"); err == nil && value == cvData["email"] {
+			fmt.Println("✅ Rotating a derived field moves it to its own key")
+		} else {
+			fmt.Printf("❌ Rotated derived field did not decrypt: %v\n", err)
+		}
+	} else {
+		fmt.Printf("❌ Failed to rotate derived field: %v\n", err)
+	}
+}
+
+// TestFieldCollisions tests that every field-adding entry point rejects
+// existing fields unless overwriting is enabled
+func TestFieldCollisions(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: FIELD COLLISIONS")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+
+	other := securecv.NewSecureCV()
+	other.LoadCV(map[string]interface{}{"email": "other@example.com", "website": "example.com"}, "multi")
+
+	attempts := map[string]func() error{
+		"SetField":            func() error { return cv.SetField("email", "x@example.com", "multi") },
+		"AddField":            func() error { return cv.AddField("email", "x@example.com", "single") },
+		"Merge":               func() error { return cv.Merge(other) },
+		"LoadPartialField":    func() error { return cv.LoadPartialField("email", "x@example.com", regexp.MustCompile(`x`)) },
+		"LoadFieldFromReader": func() error { return cv.LoadFieldFromReader("email", strings.NewReader("x@example.com")) },
+	}
+	names := make([]string, 0, len(attempts))
+	for name := range attempts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var existsErr *securecv.FieldExistsError
+		err := attempts[name]()
+		if errors.As(err, &existsErr) && strings.Contains(err.Error(), "field already exists") && existsErr.Fields[0] == "email" {
+			fmt.Printf("✅ %s rejects an existing field: %v\n", name, err)
+		} else {
+			fmt.Printf("❌ %s did not reject an existing field: %v\n", name, err)
+		}
+	}
+	if email, _ := cv.GetField("email"); email == cvData["email"] {
+		fmt.Println("✅ Rejected writes left the field unchanged")
+	} else {
+		fmt.Printf("❌ Field changed by a rejected write: %v\n", email)
+	}
+
+	cv.SetAllowOverwrite(true)
+	if err := cv.AddField("email", "x@example.com", "single"); err != nil {
+		fmt.Println("✅ AddField still rejects existing fields with overwrite enabled")
+	} else {
+		fmt.Println("❌ AddField overwrote a field")
+	}
+	if err := cv.Merge(other); err == nil {
+		email, _ := cv.GetField("email")
+		website, _ := cv.GetField("website")
+		if email == "other@example.com" && website == "example.com" {
+			fmt.Println("✅ Merge with overwrite enabled takes the other CV's fields")
+		} else {
+			fmt.Printf("❌ Unexpected merged values: %v, %v\n", email, website)
+		}
+	} else {
+		fmt.Printf("❌ Merge with overwrite enabled failed: %v\n", err)
+	}
+	if err := cv.AddField("github", "https://github.com/violet", "multi"); err == nil {
+		fmt.Println("✅ AddField adds a new field")
+	} else {
+		fmt.Printf("❌ AddField failed for a new field: %v\n", err)
+	}
+}
+
+// capturingLogger records every message it is given
+type capturingLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+// Printf implements logging.Logger
+func (cl *capturingLogger) Printf(format string, v ...interface{}) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.lines = append(cl.lines, fmt.Sprintf(format, v...))
+}
+
+// contains reports whether any captured message contains substr
+func (cl *capturingLogger) contains(substr string) bool {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	for _, line := range cl.lines {
+		if strings.Contains(line, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// TestLogger tests routing library messages through an injected logger
+func TestLogger(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: LOGGER")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	logger := &capturingLogger{}
+	cv := securecv.NewSecureCV()
+	cv.SetLogger(logger)
+	cv.LoadCV(cvData, "multi")
+	cvFile := filepath.Join(dir, "logged_cv.json")
+	cv.SaveEncryptedCV(cvFile)
+	cv.RevokeFieldKey("phone")
+
+	expected := []string{
+		fmt.Sprintf("Loading %d CV fields in 'multi' mode", len(cvData)),
+		fmt.Sprintf("Encrypted %d fields with %d keys", len(cvData), len(cvData)),
+		"Saved data to " + cvFile,
+		"Revoked key",
+	}
+	missing := 0
+	for _, message := range expected {
+		if !logger.contains(message) {
+			fmt.Printf("❌ Missing log message %q\n", message)
+			missing++
+		}
+	}
+	if missing == 0 {
+		fmt.Printf("✅ Load, save and key chain messages captured (%d lines)\n", len(logger.lines))
+	}
+
+	cv.SetLogger(nil)
+	captured := len(logger.lines)
+	cv.RotateFieldKey("email")
+	if len(logger.lines) == captured {
+		fmt.Println("✅ SetLogger(nil) silences the SecureCV and its key chain")
+	} else {
+		fmt.Printf("❌ %d messages logged after SetLogger(nil)\n", len(logger.lines)-captured)
+	}
+}
+
+// cancelAfterContext reports itself cancelled once Err has been called more
+// than allowed times
+type cancelAfterContext struct {
+	context.Context
+	allowed int
+	calls   int
+}
+
+// Err implements context.Context
+func (c *cancelAfterContext) Err() error {
+	c.calls++
+	if c.calls > c.allowed {
+		return context.Canceled
+	}
+	return nil
+}
+
+// TestLoadCVContext tests that a cancelled load is rolled back
+func TestLoadCVContext(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: LOAD CV CONTEXT")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	ctx := &cancelAfterContext{Context: context.Background(), allowed: 1}
+	err := cv.LoadCVContext(ctx, cvData, "multi")
+	if errors.Is(err, context.Canceled) {
+		fmt.Printf("✅ Cancelled load returned the context error: %v\n", err)
+	} else {
+		fmt.Printf("❌ Expected context.Canceled, got %v\n", err)
+	}
+	if len(cv.Topology().Fields) == 0 && cv.KeyChain().Size() == 0 {
+		fmt.Println("✅ Field loaded before cancellation was rolled back, leaving the CV empty")
+	} else {
+		fmt.Printf("❌ CV left with %d fields and %d keys\n", len(cv.Topology().Fields), cv.KeyChain().Size())
+	}
+
+	single := securecv.NewSecureCV()
+	single.LoadCV(map[string]interface{}{"email": "old@example.com"}, "single")
+	sharedKey := single.KeyChain().GetCurrentKey().KeyID
+	timeout, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-timeout.Done()
+	err = single.LoadCVContext(timeout, cvData, "single")
+	email, _ := single.GetField("email")
+	if errors.Is(err, context.DeadlineExceeded) && email == "old@example.com" && len(single.Topology().Fields) == 1 && single.KeyChain().GetCurrentKey().KeyID == sharedKey {
+		fmt.Println("✅ Timed-out load left the existing field and key untouched")
+	} else {
+		fmt.Printf("❌ Timed-out load: %v, email %v, %d keys\n", err, email, single.KeyChain().Size())
+	}
+
+	overwrite := securecv.NewSecureCV()
+	overwrite.LoadCV(map[string]interface{}{"phone": "+1 555 0100"}, "multi")
+	err = overwrite.LoadCVContext(&cancelAfterContext{Context: context.Background(), allowed: 8}, cvData, "multi")
+	phone, phoneErr := overwrite.GetField("phone")
+	if errors.Is(err, context.Canceled) && phoneErr == nil && phone == "+1 555 0100" && overwrite.KeyChain().Size() == 1 && overwrite.KeyChain().GetCurrentKey() != nil {
+		fmt.Println("✅ Overwritten field restored to its previous value and key")
+	} else {
+		fmt.Printf("❌ Overwritten field after rollback: %v (%v), %d keys\n", phone, phoneErr, overwrite.KeyChain().Size())
+	}
+
+	complete := securecv.NewSecureCV()
+	if err := complete.LoadCVContext(context.Background(), cvData, "multi"); err == nil && len(complete.Topology().Fields) == len(cvData) {
+		fmt.Printf("✅ Uncancelled load encrypted all %d fields\n", len(cvData))
+	} else {
+		fmt.Printf("❌ Uncancelled load failed: %v\n", err)
+	}
+}
+
+// TestMaxFieldSize tests rejecting fields over the configured size limit
+func TestMaxFieldSize() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: MAX FIELD SIZE")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.SetMaxFieldSize(10)
+
+	err := cv.LoadCV(map[string]interface{}{"name": "Violet", "summary": "Far more than ten bytes"}, "multi")
+	if err != nil && strings.Contains(err.Error(), "field exceeds max size") && strings.Contains(err.Error(), "summary") {
+		fmt.Printf("✅ Oversized field rejected: %v\n", err)
+	} else {
+		fmt.Printf("❌ Oversized field not rejected: %v\n", err)
+	}
+	if len(cv.Topology().Fields) == 0 {
+		fmt.Println("✅ Rejected load encrypted nothing")
+	} else {
+		fmt.Printf("❌ Rejected load stored %d fields\n", len(cv.Topology().Fields))
+	}
+
+	if err := cv.LoadCV(map[string]interface{}{"name": "Violet"}, "single"); err == nil {
+		fmt.Println("✅ Field within the limit accepted")
+	} else {
+		fmt.Printf("❌ Short field rejected: %v\n", err)
+	}
+
+	tooLong := []struct {
+		name string
+		add  func() error
+	}{
+		{"SetField", func() error { return cv.SetField("title", "Principal Engineer", "single") }},
+		{"LoadPartialField", func() error { return cv.LoadPartialField("phone", "+1 555 0100 ext 7", regexp.MustCompile(`\d+`)) }},
+		{"LoadFieldFromReader", func() error { return cv.LoadFieldFromReader("bio", strings.NewReader(strings.Repeat("x", 64))) }},
+	}
+	for _, attempt := range tooLong {
+		if err := attempt.add(); err != nil && strings.Contains(err.Error(), "field exceeds max size") {
+			fmt.Printf("✅ %s rejects an oversized field\n", attempt.name)
+		} else {
+			fmt.Printf("❌ %s accepted an oversized field: %v\n", attempt.name, err)
+		}
+	}
+
+	cv.SetMaxFieldSize(0)
+	if err := cv.SetField("title", "Principal Engineer", "single"); err == nil {
+		fmt.Println("✅ SetMaxFieldSize(0) removes the limit")
+	} else {
+		fmt.Printf("❌ Field rejected with no limit: %v\n", err)
+	}
+}
+
+// TestDiffFields tests classifying field differences between two CVs
+func TestDiffFields(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: DIFF FIELDS")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	base := securecv.NewSecureCV()
+	base.LoadCV(cvData, "multi")
+
+	modifiedData := make(map[string]interface{}, len(cvData))
+	for field, value := range cvData {
+		modifiedData[field] = value
+	}
+	modifiedData["email"] = "new.address@example.com"
+	modifiedData["github"] = "https://github.com/violet"
+	delete(modifiedData, "phone")
+	modified := securecv.NewSecureCV()
+	modified.LoadCV(modifiedData, "single")
+
+	added, removed, changed, err := securecv.DiffFields(base, modified)
+	if err != nil {
+		fmt.Printf("❌ DiffFields failed: %v\n", err)
+		return
+	}
+	if reflect.DeepEqual(added, []string{"github"}) && reflect.DeepEqual(removed, []string{"phone"}) && reflect.DeepEqual(changed, []string{"email"}) {
+		fmt.Println("✅ Added, removed and changed fields classified correctly")
+	} else {
+		fmt.Printf("❌ Diff: added %v, removed %v, changed %v\n", added, removed, changed)
+	}
+
+	copyCV := securecv.NewSecureCV()
+	copyCV.LoadCV(cvData, "single")
+	added, removed, changed, err = securecv.DiffFields(base, copyCV)
+	if err == nil && len(added)+len(removed)+len(changed) == 0 {
+		fmt.Println("✅ Same values under different keys show no differences")
+	} else {
+		fmt.Printf("❌ Identical CVs differ: %v %v %v (%v)\n", added, removed, changed, err)
+	}
+
+	modified.RevokeFieldKey("email")
+	if _, _, _, err := securecv.DiffFields(base, modified); err != nil {
+		fmt.Printf("✅ Undecryptable CV rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Diff succeeded with a revoked field")
+	}
+}
+
+// TestTypedStats tests the typed Stats struct against a known load
+func TestTypedStats(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: TYPED STATS")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "single")
+	stats := cv.Stats()
+	currentKey := cv.KeyChain().GetCurrentKey().KeyID
+	if stats.TotalFields == 10 && stats.TotalKeys == 1 && stats.ActiveKeys == 1 && stats.RevokedKeys == 0 && stats.CurrentKeyID == currentKey {
+		fmt.Printf("✅ Single mode stats: %d fields, %d key\n", stats.TotalFields, stats.TotalKeys)
+	} else {
+		fmt.Printf("❌ Unexpected stats: %+v\n", stats)
+	}
+
+	legacy := cv.GetStats()
+	if legacy["total_fields"] == stats.TotalFields && legacy["total_keys"] == stats.TotalKeys && legacy["current_key_id"] == stats.CurrentKeyID {
+		fmt.Println("✅ Stats agrees with GetStats")
+	} else {
+		fmt.Printf("❌ Stats %+v disagrees with GetStats %v\n", stats, legacy)
+	}
+
+	cv.RotateFieldKey("email")
+	cv.RevokeKey(currentKey)
+	stats = cv.Stats()
+	encoded, _ := json.Marshal(stats)
+	if stats.TotalKeys == 2 && stats.ActiveKeys == 1 && stats.RevokedKeys == 1 && strings.Contains(string(encoded), `"revoked_keys":1`) {
+		fmt.Printf("✅ Stats track rotation and revocation: %s\n", encoded)
+	} else {
+		fmt.Printf("❌ Stats after revocation: %s\n", encoded)
+	}
+}
+
+// TestMalformedCiphertext tests the errors DecryptData gives for a wrong-length
+// nonce and a truncated ciphertext
+func TestMalformedCiphertext() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: MALFORMED CIPHERTEXT")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	key := cryptoutils.GenerateRandomBytes(32)
+	encrypted, err := cryptoutils.EncryptData("Violet", key, nil)
+	if err != nil {
+		fmt.Printf("❌ Failed to encrypt: %v\n", err)
+		return
+	}
+
+	shortNonce := *encrypted
+	shortNonce.Nonce = base64.StdEncoding.EncodeToString(make([]byte, 8))
+	if _, err := cryptoutils.DecryptData(&shortNonce, key, nil); err != nil && strings.Contains(err.Error(), "invalid nonce length") {
+		fmt.Printf("✅ Wrong-length nonce reported: %v\n", err)
+	} else {
+		fmt.Printf("❌ Wrong-length nonce: %v\n", err)
+	}
+
+	empty := *encrypted
+	empty.Ciphertext = ""
+	if _, err := cryptoutils.DecryptData(&empty, key, nil); err != nil && strings.Contains(err.Error(), "ciphertext too short") {
+		fmt.Printf("✅ Empty ciphertext reported: %v\n", err)
+	} else {
+		fmt.Printf("❌ Empty ciphertext: %v\n", err)
+	}
+
+	if value, err := cryptoutils.DecryptData(encrypted, key, nil); err == nil && value == "Violet" {
+		fmt.Println("✅ Well-formed ciphertext still decrypts")
+	} else {
+		fmt.Printf("❌ Well-formed ciphertext failed: %v\n", err)
+	}
+}
+
+// TestFieldsForKey tests listing the fields each key protects
+func TestFieldsForKey(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: FIELDS FOR KEY")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	allFields := make([]string, 0, len(cvData))
+	for field := range cvData {
+		allFields = append(allFields, field)
+	}
+	sort.Strings(allFields)
+
+	single := securecv.NewSecureCV()
+	single.LoadCV(cvData, "single")
+	fields, err := single.FieldsForKey(single.KeyChain().GetCurrentKey().KeyID)
+	if err == nil && reflect.DeepEqual(fields, allFields) {
+		fmt.Printf("✅ Single-mode key protects all %d fields\n", len(fields))
+	} else {
+		fmt.Printf("❌ Single-mode key lists %v (%v)\n", fields, err)
+	}
+
+	multi := securecv.NewSecureCV()
+	multi.LoadCV(cvData, "multi")
+	mismatched := 0
+	for field, keyID := range multi.Topology().Fields {
+		if fields, err := multi.FieldsForKey(keyID); err != nil || !reflect.DeepEqual(fields, []string{field}) {
+			mismatched++
+		}
+	}
+	if mismatched == 0 {
+		fmt.Println("✅ Each multi-mode key protects exactly its own field")
+	} else {
+		fmt.Printf("❌ %d multi-mode keys list the wrong fields\n", mismatched)
+	}
+
+	if _, err := multi.FieldsForKey("no-such-key"); err != nil && strings.Contains(err.Error(), "not found") {
+		fmt.Printf("✅ Unknown key rejected: %v\n", err)
+	} else {
+		fmt.Printf("❌ Unknown key not rejected: %v\n", err)
+	}
+}
+
+// TestKeyUsageQuota tests automatic rotation once a key's encryption quota
+// is used up
+func TestKeyUsageQuota() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: KEY USAGE QUOTA")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	key := cv.KeyChain().CreateKey()
+	if err := cv.KeyChain().SetMaxUsage(key.KeyID, 2); err != nil {
+		fmt.Printf("❌ Failed to set max usage: %v\n", err)
+		return
+	}
+
+	values := map[string]string{"name": "Violet", "email": "violet@example.com", "phone": "+1 555 0100"}
+	keyAfter := make(map[string]string)
+	for _, field := range []string{"name", "email", "phone"} {
+		cv.SetField(field, values[field], "single")
+		keyAfter[field] = cv.KeyChain().GetCurrentKey().KeyID
+	}
+
+	rotated := cv.KeyChain().GetNode(keyAfter["phone"])
+	if keyAfter["name"] == key.KeyID && keyAfter["email"] == key.KeyID && rotated != nil && rotated.KeyID != key.KeyID && rotated.RotatedFrom == key.KeyID {
+		fmt.Println("✅ Third encryption rotated to a new key after a quota of 2")
+	} else {
+		fmt.Printf("❌ Keys used: %v (original %s)\n", keyAfter, key.KeyID)
+	}
+	if key.UsageCount == 2 && rotated != nil && rotated.UsageCount == 1 && rotated.MaxUsage == 2 {
+		fmt.Println("✅ Usage counted per key and the quota carried to the new key")
+	} else {
+		fmt.Printf("❌ Usage counts: original %d, new %v\n", key.UsageCount, rotated)
+	}
+
+	failures := 0
+	for field, value := range values {
+		if decrypted, err := cv.GetField(field); err != nil || decrypted != value {
+			failures++
+		}
+	}
+	if failures == 0 {
+		fmt.Println("✅ Fields on both keys still decrypt")
+	} else {
+		fmt.Printf("❌ %d fields failed to decrypt\n", failures)
+	}
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	keysFile := filepath.Join(dir, "quota_keys.json")
+	cv.SaveKeys(keysFile)
+	reloaded := securecv.NewSecureCV()
+	reloaded.LoadKeys(keysFile)
+	if node := reloaded.KeyChain().GetNode(key.KeyID); node != nil && node.UsageCount == 2 && node.MaxUsage == 2 {
+		fmt.Println("✅ Usage count and quota survive SaveKeys / LoadKeys")
+	} else {
+		fmt.Println("❌ Usage count or quota lost on reload")
+	}
+}
+
+// TestLegacyUntypedRecords tests best-effort decoding of records without a Type
+func TestLegacyUntypedRecords() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: LEGACY UNTYPED RECORDS")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	key := cryptoutils.GenerateRandomBytes(32)
+	untyped := func(plaintext string) *models.EncryptedData {
+		encrypted, _ := cryptoutils.EncryptData(plaintext, key, nil)
+		encrypted.Type = ""
+		return encrypted
+	}
+
+	value, err := cryptoutils.DecryptData(untyped(`{"degree": "BSc", "year": 2015}`), key, nil)
+	if object, ok := value.(map[string]interface{}); err == nil && ok && object["degree"] == "BSc" {
+		fmt.Println("✅ Untyped JSON object decodes to a map")
+	} else {
+		fmt.Printf("❌ Untyped JSON object decoded to %T %v (%v)\n", value, value, err)
+	}
+
+	value, err = cryptoutils.DecryptData(untyped(`["Go", "Rust"]`), key, nil)
+	if list, ok := value.([]interface{}); err == nil && ok && len(list) == 2 {
+		fmt.Println("✅ Untyped JSON array decodes to a slice")
+	} else {
+		fmt.Printf("❌ Untyped JSON array decoded to %T %v (%v)\n", value, value, err)
+	}
+
+	value, err = cryptoutils.DecryptData(untyped("42"), key, nil)
+	if err == nil && value == "42" {
+		fmt.Println("✅ Untyped scalar stays a string")
+	} else {
+		fmt.Printf("❌ Untyped scalar decoded to %T %v (%v)\n", value, value, err)
+	}
+
+	typed, _ := cryptoutils.EncryptData(`{"degree": "BSc"}`, key, nil)
+	if value, err := cryptoutils.DecryptData(typed, key, nil); err == nil && value == `{"degree": "BSc"}` {
+		fmt.Println("✅ Explicitly typed string holding JSON is unchanged")
+	} else {
+		fmt.Printf("❌ Typed string decoded to %T %v (%v)\n", value, value, err)
+	}
+}
+
+// TestRotateAndShare tests rotating a field and getting its new key in one step
+func TestRotateAndShare(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: ROTATE AND SHARE")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	stale, _ := cv.GetShareableKey("email")
+
+	shared, err := cv.RotateAndShare("email")
+	if err != nil {
+		fmt.Printf("❌ RotateAndShare failed: %v\n", err)
+		return
+	}
+	if shared.KeyID == cv.Topology().Fields["email"] && shared.KeyID != stale.KeyID {
+		fmt.Printf("✅ Returned key is the field's new key: %s...\n", models.ShortID(shared.KeyID, 8))
+	} else {
+		fmt.Printf("❌ Returned key %s, field uses %s\n", shared.KeyID, cv.Topology().Fields["email"])
+	}
+
+	bundle, _ := cv.ExportFieldBundle("email")
+	if value, err := securecv.DecryptField(bundle.Encrypted, shared); err == nil && value == cvData["email"] {
+		fmt.Println("✅ Returned key decrypts the rotated field")
+	} else {
+		fmt.Printf("❌ Returned key failed to decrypt: %v\n", err)
+	}
+	if _, err := securecv.DecryptField(bundle.Encrypted, stale); err != nil {
+		fmt.Println("✅ Previously shared key no longer decrypts")
+	} else {
+		fmt.Println("❌ Stale key still decrypts the rotated field")
+	}
+
+	if _, err := cv.RotateAndShare("missing"); err != nil {
+		fmt.Printf("✅ Unknown field rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Unknown field accepted")
+	}
+}
+
+// TestHexKeys tests exporting and importing hex-encoded key material
+func TestHexKeys(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: HEX KEYS")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	shared, _ := cv.GetShareableKey("email")
+
+	fromBase64, errBase64 := models.ShareableKey{Key: shared.Key}.DecodeKey()
+	fromHex, errHex := models.ShareableKey{KeyHex: shared.KeyHex}.DecodeKey()
+	keyBytes, _ := cv.KeyChain().GetKeyBytes(shared.KeyID)
+	if errBase64 == nil && errHex == nil && bytes.Equal(fromBase64, fromHex) && bytes.Equal(fromHex, keyBytes) {
+		fmt.Println("✅ Base64 and hex encodings reconstruct identical key bytes")
+	} else {
+		fmt.Printf("❌ Decoded keys differ (%v, %v)\n", errBase64, errHex)
+	}
+
+	// A manifest carrying only hex keys loads and decrypts
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	cvFile := filepath.Join(dir, "hex_cv.json")
+	keysFile := filepath.Join(dir, "hex_keys.json")
+	cv.SaveEncryptedCV(cvFile)
+	manifest := cv.GetAllKeys()
+	for keyID, key := range manifest.Keys {
+		key.Key = ""
+		manifest.Keys[keyID] = key
+	}
+	fileio.SaveJSON(keysFile, manifest)
+	if loaded, err := securecv.LoadPair(cvFile, keysFile); err == nil {
+		if email, err := loaded.GetField("email"); err == nil && email == cvData["email"] {
+			fmt.Println("✅ Hex-only manifest loads and decrypts")
+		} else {
+			fmt.Printf("❌ Hex-only manifest failed to decrypt: %v\n", err)
+		}
+	} else {
+		fmt.Printf("❌ Hex-only manifest failed to load: %v\n", err)
+	}
+
+	mismatched := *shared
+	mismatched.KeyHex = hex.EncodeToString(cryptoutils.GenerateRandomBytes(32))
+	bundle, _ := cv.ExportFieldBundle("email")
+	if _, err := securecv.DecryptField(bundle.Encrypted, &mismatched); err != nil && strings.Contains(err.Error(), "disagree") {
+		fmt.Printf("✅ Disagreeing key and key_hex rejected: %v\n", err)
+	} else {
+		fmt.Printf("❌ Disagreeing encodings not rejected: %v\n", err)
+	}
+}
+
+// failingReader always fails, simulating an RNG that cannot be read
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("entropy source unavailable")
+}
+
+// TestRandomFailure tests that a failing RNG surfaces as errors rather than
+// panics
+func TestRandomFailure() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: RANDOM FAILURE")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(map[string]interface{}{"name": "Violet K."}, "single")
+
+	cryptoutils.SetRandReader(failingReader{})
+	cryptoutils.SetKeyRandReader(failingReader{})
+	defer cryptoutils.SetRandReader(nil)
+	defer cryptoutils.SetKeyRandReader(nil)
+
+	// noPanic runs f and reports a panic as an error
+	noPanic := func(f func() error) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panicked: %v", r)
+			}
+		}()
+		return f()
+	}
+
+	if _, err := cryptoutils.GenerateRandomBytesErr(32); err != nil && strings.Contains(err.Error(), "entropy source unavailable") {
+		fmt.Printf("✅ GenerateRandomBytesErr returns the RNG error: %v\n", err)
+	} else {
+		fmt.Printf("❌ GenerateRandomBytesErr did not fail: %v\n", err)
+	}
+
+	err := noPanic(func() error {
+		_, err := cv.KeyChain().CreateKeyErr()
+		return err
+	})
+	if err != nil && !strings.Contains(err.Error(), "panicked") {
+		fmt.Printf("✅ CreateKeyErr returns an error: %v\n", err)
+	} else {
+		fmt.Printf("❌ CreateKeyErr did not return an error: %v\n", err)
+	}
+
+	err = noPanic(func() error {
+		_, err := cryptoutils.EncryptData("secret", make([]byte, 32), nil)
+		return err
+	})
+	if err != nil && !strings.Contains(err.Error(), "panicked") {
+		fmt.Printf("✅ EncryptData returns an error: %v\n", err)
+	} else {
+		fmt.Printf("❌ EncryptData did not return an error: %v\n", err)
+	}
+
+	fresh := securecv.NewSecureCV()
+	err = noPanic(func() error {
+		return fresh.LoadCV(map[string]interface{}{"email": "Violet.tech@Violet.com"}, "multi")
+	})
+	if err != nil && !strings.Contains(err.Error(), "panicked") {
+		fmt.Printf("✅ LoadCV returns an error: %v\n", err)
+	} else {
+		fmt.Printf("❌ LoadCV did not return an error: %v\n", err)
+	}
+
+	err = noPanic(func() error {
+		_, err := cv.RotateFieldKey("name")
+		return err
+	})
+	if err != nil && !strings.Contains(err.Error(), "panicked") {
+		fmt.Printf("✅ RotateFieldKey returns an error: %v\n", err)
+	} else {
+		fmt.Printf("❌ RotateFieldKey did not return an error: %v\n", err)
+	}
+
+	recipient, _ := rsa.GenerateKey(rand.Reader, 2048)
+	others := map[string]func() error{
+		"Backup": func() error {
+			_, err := cv.KeyChain().Backup("passphrase")
+			return err
+		},
+		"EncryptJWE": func() error {
+			_, err := cryptoutils.EncryptJWE([]byte("secret"), "", &recipient.PublicKey)
+			return err
+		},
+		"EncryptStream": func() error {
+			return cryptoutils.EncryptStream(strings.NewReader("secret"), io.Discard, make([]byte, 32))
+		},
+	}
+	for _, name := range []string{"Backup", "EncryptJWE", "EncryptStream"} {
+		if err := noPanic(others[name]); err != nil && !strings.Contains(err.Error(), "panicked") {
+			fmt.Printf("✅ %s returns an error: %v\n", name, err)
+		} else {
+			fmt.Printf("❌ %s did not return an error: %v\n", name, err)
+		}
+	}
+
+	if name, err := cv.GetField("name"); err == nil && name == "Violet K." {
+		fmt.Println("✅ Failed rotation left the field readable")
+	} else {
+		fmt.Printf("❌ Field unreadable after failed rotation: %v\n", err)
+	}
+
+	cryptoutils.SetRandReader(nil)
+	cryptoutils.SetKeyRandReader(nil)
+	if _, err := cv.KeyChain().CreateKeyErr(); err == nil {
+		fmt.Println("✅ Key creation recovers once the RNG works again")
+	} else {
+		fmt.Printf("❌ Key creation still failing: %v\n", err)
+	}
+}
+
+// TestShareWithRecipients tests wrapping a field key for several recipients
+func TestShareWithRecipients(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: SHARE WITH RECIPIENTS")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+
+	aliceKEK := cryptoutils.GenerateRandomBytes(32)
+	bobKEK := cryptoutils.GenerateRandomBytes(32)
+	carolKEK := cryptoutils.GenerateRandomBytes(32)
+
+	share, err := cv.ShareFieldWith("email", map[string][]byte{"alice": aliceKEK, "bob": bobKEK})
+	if err != nil {
+		fmt.Printf("❌ ShareFieldWith failed: %v\n", err)
+		return
+	}
+	if len(share.Recipients) == 2 {
+		fmt.Println("✅ One wrapped key per recipient")
+	} else {
+		fmt.Printf("❌ Expected 2 wrapped keys, got %d\n", len(share.Recipients))
+	}
+
+	dek, _ := cv.KeyChain().GetKeyBytes(share.KeyID)
+	aliceDEK, errAlice := cryptoutils.UnwrapKey(share.Recipients["alice"], aliceKEK)
+	bobDEK, errBob := cryptoutils.UnwrapKey(share.Recipients["bob"], bobKEK)
+	if errAlice == nil && errBob == nil && bytes.Equal(aliceDEK, dek) && bytes.Equal(bobDEK, dek) {
+		fmt.Println("✅ Each recipient unwraps the same field key with their own KEK")
+	} else {
+		fmt.Printf("❌ Recipients unwrapped different keys (%v, %v)\n", errAlice, errBob)
+	}
+
+	for _, id := range []string{"alice", "bob"} {
+		kek := map[string][]byte{"alice": aliceKEK, "bob": bobKEK}[id]
+		if email, err := securecv.OpenRecipientShare(share, id, kek); err == nil && email == cvData["email"] {
+			fmt.Printf("✅ %s decrypts the shared field\n", id)
+		} else {
+			fmt.Printf("❌ %s failed to decrypt: %v\n", id, err)
+		}
+	}
+
+	if _, err := cryptoutils.UnwrapKey(share.Recipients["alice"], carolKEK); err != nil {
+		fmt.Printf("✅ Third recipient cannot unwrap another's key: %v\n", err)
+	} else {
+		fmt.Println("❌ Third recipient unwrapped alice's key")
+	}
+	if _, err := securecv.OpenRecipientShare(share, "carol", carolKEK); err != nil {
+		fmt.Printf("✅ Third recipient not in the share rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Third recipient decrypted the share")
+	}
+
+	if _, err := cv.ShareFieldWith("email", map[string][]byte{"alice": aliceKEK, "dave": []byte("short")}); err != nil {
+		fmt.Printf("✅ Invalid recipient KEK rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Invalid recipient KEK accepted")
+	}
+}
+
+// TestCanonicalJSON tests that equal values serialize identically before
+// encryption
+func TestCanonicalJSON() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: CANONICAL JSON")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	// Same logical value built in different insertion orders and forms
+	first := map[string]interface{}{}
+	first["name"] = "Violet K."
+	first["skills"] = []interface{}{"Go", "Rust"}
+	first["address"] = map[string]interface{}{"city": "Lisbon", "zip": "1000"}
+
+	second := map[string]interface{}{}
+	second["address"] = json.RawMessage(`{ "zip": "1000",  "city": "Lisbon" }`)
+	second["skills"] = []string{"Go", "Rust"}
+	second["name"] = "Violet K."
+
+	canonicalFirst, errFirst := cryptoutils.CanonicalJSON(first)
+	canonicalSecond, errSecond := cryptoutils.CanonicalJSON(second)
+	if errFirst == nil && errSecond == nil && bytes.Equal(canonicalFirst, canonicalSecond) {
+		fmt.Printf("✅ Equal values canonicalize identically: %s\n", canonicalFirst)
+	} else {
+		fmt.Printf("❌ Canonical forms differ: %s vs %s (%v, %v)\n", canonicalFirst, canonicalSecond, errFirst, errSecond)
+	}
+
+	key := cryptoutils.GenerateRandomBytes(32)
+	var decrypted [][]byte
+	for _, value := range []interface{}{first, second} {
+		encrypted, err := cryptoutils.EncryptData(value, key, nil)
+		if err != nil {
+			fmt.Printf("❌ Encryption failed: %v\n", err)
+			return
+		}
+		plain, err := cryptoutils.DecryptData(encrypted, key, nil)
+		if err != nil {
+			fmt.Printf("❌ Decryption failed: %v\n", err)
+			return
+		}
+		canonical, _ := cryptoutils.CanonicalJSON(plain)
+		decrypted = append(decrypted, canonical)
+	}
+	if bytes.Equal(decrypted[0], decrypted[1]) && bytes.Equal(decrypted[0], canonicalFirst) {
+		fmt.Println("✅ Decrypted values have matching canonical forms")
+	} else {
+		fmt.Printf("❌ Decrypted canonical forms differ: %s vs %s\n", decrypted[0], decrypted[1])
+	}
+
+	if canonical, err := cryptoutils.CanonicalJSON(json.RawMessage(`{"n": 1.50, "big": 12345678901234567890}`)); err == nil && string(canonical) == `{"big":12345678901234567890,"n":1.50}` {
+		fmt.Printf("✅ Number literals preserved: %s\n", canonical)
+	} else {
+		fmt.Printf("❌ Numbers changed: %s (%v)\n", canonical, err)
+	}
+}
+
+// TestSelfTest tests the boot-time crypto self test
+func TestSelfTest() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: SELF TEST")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	if err := cryptoutils.SelfTest(); err == nil {
+		fmt.Println("✅ SelfTest passes on a healthy build")
+	} else {
+		fmt.Printf("❌ SelfTest failed: %v\n", err)
+	}
+
+	cryptoutils.SetKeyRandReader(failingReader{})
+	err := cryptoutils.SelfTest()
+	cryptoutils.SetKeyRandReader(nil)
+	if err != nil && strings.Contains(err.Error(), "key generation failed") {
+		fmt.Printf("✅ SelfTest reports a broken RNG: %v\n", err)
+	} else {
+		fmt.Printf("❌ Broken RNG not reported: %v\n", err)
+	}
+}
+
+// TestKeyChainIterate tests walking the key chain in creation order
+func TestKeyChainIterate(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: KEY CHAIN ITERATE")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	kc := keychain.NewKeyChain()
+	var created []string
+	for i := 0; i < 5; i++ {
+		created = append(created, kc.CreateKey().KeyID)
+	}
+	kc.RevokeKey(created[2])
+
+	var walked []string
+	positionsOK := true
+	kc.Iterate(func(pos int, node *models.KeyNode) bool {
+		positionsOK = positionsOK && pos == len(walked)
+		walked = append(walked, node.KeyID)
+		return true
+	})
+	if reflect.DeepEqual(walked, created) && positionsOK {
+		fmt.Println("✅ Iteration follows creation order, revoked keys included")
+	} else {
+		fmt.Printf("❌ Iteration order %v, created %v\n", walked, created)
+	}
+
+	visited := 0
+	kc.Iterate(func(pos int, node *models.KeyNode) bool {
+		visited++
+		return pos < 1
+	})
+	if visited == 2 {
+		fmt.Println("✅ Iteration stops when the callback returns false")
+	} else {
+		fmt.Printf("❌ Visited %d keys after stopping at position 1\n", visited)
+	}
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	var chainOrder []string
+	cv.KeyChain().Iterate(func(_ int, node *models.KeyNode) bool {
+		chainOrder = append(chainOrder, node.KeyID)
+		return true
+	})
+	first, _ := json.Marshal(cv.GetAllKeys())
+	second, _ := json.Marshal(cv.GetAllKeys())
+	if manifest := cv.GetAllKeys(); reflect.DeepEqual(manifest.Order, chainOrder) && bytes.Equal(first, second) {
+		fmt.Printf("✅ GetAllKeys lists %d keys in chain order, identically each call\n", len(manifest.Order))
+	} else {
+		fmt.Printf("❌ GetAllKeys order %v, chain %v\n", manifest.Order, chainOrder)
+	}
+}
+
+// TestCombinedFormat tests the single-blob nonce||ciphertext interop format
+func TestCombinedFormat() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: COMBINED FORMAT")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	key := cryptoutils.GenerateRandomBytes(32)
+	aad := cryptoutils.FieldAAD("email", nil)
+	plaintext := []byte("Violet.tech@Violet.com")
+
+	combined, err := cryptoutils.EncryptCombined(plaintext, key, aad)
+	if err != nil {
+		fmt.Printf("❌ EncryptCombined failed: %v\n", err)
+		return
+	}
+	if decrypted, err := cryptoutils.DecryptCombined(combined, key, aad); err == nil && bytes.Equal(decrypted, plaintext) {
+		fmt.Println("✅ Combined format round-trips")
+	} else {
+		fmt.Printf("❌ Combined round trip failed: %v\n", err)
+	}
+
+	// Another library would split the blob the same way
+	blob, _ := base64.StdEncoding.DecodeString(combined)
+	if block, err := aes.NewCipher(key); err == nil {
+		gcm, _ := cipher.NewGCM(block)
+		if opened, err := gcm.Open(nil, blob[:12], blob[12:], aad); err == nil && bytes.Equal(opened, plaintext) {
+			fmt.Println("✅ Blob opens with a plain AES-GCM implementation")
+		} else {
+			fmt.Printf("❌ Plain AES-GCM could not open the blob: %v\n", err)
+		}
+	}
+
+	if _, err := cryptoutils.DecryptCombined(combined, key, cryptoutils.FieldAAD("phone", nil)); err != nil {
+		fmt.Println("✅ Wrong associated data rejected")
+	} else {
+		fmt.Println("❌ Wrong associated data accepted")
+	}
+	if _, err := cryptoutils.DecryptCombined(base64.StdEncoding.EncodeToString(blob[:20]), key, aad); err != nil && strings.Contains(err.Error(), "too short") {
+		fmt.Printf("✅ Truncated blob rejected: %v\n", err)
+	} else {
+		fmt.Printf("❌ Truncated blob not rejected: %v\n", err)
+	}
+
+	encrypted, err := cryptoutils.EncryptData(string(plaintext), key, aad)
+	if err == nil && encrypted.Nonce != "" && encrypted.Ciphertext != "" {
+		if value, err := cryptoutils.DecryptData(encrypted, key, aad); err == nil && value == string(plaintext) {
+			fmt.Println("✅ Split Nonce/Ciphertext format is still the default and works")
+		} else {
+			fmt.Printf("❌ Split format failed to decrypt: %v\n", err)
+		}
+	} else {
+		fmt.Printf("❌ Split format failed to encrypt: %v\n", err)
+	}
+}
+
+// TestMismatchedKeysFile tests that a keys file from another CV is caught
+// by the field digest before decryption
+func TestMismatchedKeysFile(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: MISMATCHED KEYS FILE")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	cvFile := filepath.Join(dir, "cv.json")
+	keysFile := filepath.Join(dir, "keys.json")
+	cv.SaveEncryptedCV(cvFile)
+	cv.SaveKeys(keysFile)
+
+	other := securecv.NewSecureCV()
+	other.LoadCV(map[string]interface{}{"name": "Someone Else", "github": "someone"}, "multi")
+	otherKeysFile := filepath.Join(dir, "other_keys.json")
+	other.SaveKeys(otherKeysFile)
+
+	if _, err := securecv.LoadPair(cvFile, keysFile); err == nil {
+		fmt.Println("✅ Matching CV and keys files load")
+	} else {
+		fmt.Printf("❌ Matching pair rejected: %v\n", err)
+	}
+
+	_, err = securecv.LoadPair(cvFile, otherKeysFile)
+	if err != nil && strings.Contains(err.Error(), "does not match") && strings.Contains(err.Error(), "github") && strings.Contains(err.Error(), "email") {
+		fmt.Printf("✅ Keys file from another CV rejected with the difference: %v\n", err)
+	} else {
+		fmt.Printf("❌ Mismatched pair not reported: %v\n", err)
+	}
+
+	// A CV file whose fields no longer match its own digest is rejected
+	var saved models.EncryptedCV
+	fileio.LoadJSON(cvFile, &saved)
+	delete(saved.EncryptedData, "email")
+	delete(saved.FieldKeyMap, "email")
+	truncatedFile := filepath.Join(dir, "truncated.json")
+	fileio.SaveJSON(truncatedFile, &saved)
+	if err := securecv.NewSecureCV().LoadEncryptedCV(truncatedFile); err != nil && strings.Contains(err.Error(), "field digest") {
+		fmt.Printf("✅ CV file altered after saving rejected: %v\n", err)
+	} else {
+		fmt.Printf("❌ Altered CV file accepted: %v\n", err)
+	}
+}
+
+// TestUseNumber tests decoding nested numbers as json.Number
+func TestUseNumber() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: USE NUMBER")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	profile := map[string]interface{}{
+		"employee_id": int64(9007199254740993), // 2^53 + 1, not exact as a float64
+		"history":     []interface{}{map[string]interface{}{"year": 2019, "salary": 85000.5}},
+	}
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(map[string]interface{}{"profile": profile}, "single")
+
+	value, _ := cv.GetField("profile")
+	if id, ok := value.(map[string]interface{})["employee_id"].(float64); ok {
+		fmt.Printf("✅ Numbers decode as float64 by default: %.0f\n", id)
+	} else {
+		fmt.Printf("❌ Default decoding changed: %T\n", value.(map[string]interface{})["employee_id"])
+	}
+
+	cv.SetUseNumber(true)
+	value, err := cv.GetField("profile")
+	if err != nil {
+		fmt.Printf("❌ GetField failed: %v\n", err)
+		return
+	}
+	decoded := value.(map[string]interface{})
+	id, ok := decoded["employee_id"].(json.Number)
+	if n, err := id.Int64(); ok && err == nil && n == 9007199254740993 {
+		fmt.Printf("✅ Large integer survives as json.Number: %s\n", id)
+	} else {
+		fmt.Printf("❌ Integer not preserved: %#v\n", decoded["employee_id"])
+	}
+
+	entry := decoded["history"].([]interface{})[0].(map[string]interface{})
+	year, yearOK := entry["year"].(json.Number)
+	salary, salaryOK := entry["salary"].(json.Number)
+	if yearOK && salaryOK && year.String() == "2019" && salary.String() == "85000.5" {
+		fmt.Println("✅ Numbers nested in slices keep their literal form")
+	} else {
+		fmt.Printf("❌ Nested numbers changed: %#v, %#v\n", entry["year"], entry["salary"])
+	}
+
+	if _, err := cv.RotateFieldKey("profile"); err == nil {
+		rotated, _ := cv.GetField("profile")
+		if rotated.(map[string]interface{})["employee_id"] == json.Number("9007199254740993") {
+			fmt.Println("✅ Rotation keeps the exact integer")
+		} else {
+			fmt.Printf("❌ Rotation changed the integer: %#v\n", rotated.(map[string]interface{})["employee_id"])
+		}
+	} else {
+		fmt.Printf("❌ Rotation failed: %v\n", err)
+	}
+}
+
+// TestConvertMode tests re-encrypting a CV under a different key mode
+func TestConvertMode(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: CONVERT MODE")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	var oldKeyIDs []string
+	for _, node := range cv.KeyChain().GetAllKeys() {
+		oldKeyIDs = append(oldKeyIDs, node.KeyID)
+	}
+
+	if err := cv.ConvertMode("single"); err != nil {
+		fmt.Printf("❌ ConvertMode(single) failed: %v\n", err)
+		return
+	}
+
+	keyIDs := make(map[string]bool)
+	for _, keyID := range cv.GetAllKeys().FieldMap {
+		keyIDs[keyID] = true
+	}
+	var singleKeyID string
+	for keyID := range keyIDs {
+		singleKeyID = keyID
+	}
+	if fields, _ := cv.FieldsForKey(singleKeyID); len(keyIDs) == 1 && len(fields) == len(cvData) {
+		fmt.Printf("✅ One key now covers all %d fields\n", len(fields))
+	} else {
+		fmt.Printf("❌ Fields spread over %d keys\n", len(keyIDs))
+	}
+
+	original, _ := json.Marshal(cvData)
+	converted, err := cv.OriginalJSON()
+	var roundTrip map[string]interface{}
+	json.Unmarshal(converted, &roundTrip)
+	reencoded, _ := json.Marshal(roundTrip)
+	if err == nil && bytes.Equal(original, reencoded) {
+		fmt.Println("✅ Data intact after conversion")
+	} else {
+		fmt.Printf("❌ Data changed by conversion: %v\n", err)
+	}
+
+	revoked := 0
+	for _, node := range cv.KeyChain().GetRevokedKeys() {
+		for _, keyID := range oldKeyIDs {
+			if node.KeyID == keyID {
+				revoked++
+			}
+		}
+	}
+	if revoked == len(oldKeyIDs) {
+		fmt.Printf("✅ All %d old per-field keys revoked\n", revoked)
+	} else {
+		fmt.Printf("❌ Revoked %d of %d old keys\n", revoked, len(oldKeyIDs))
+	}
+
+	if err := cv.ConvertMode("multi"); err == nil && len(cv.KeyChain().GetAllKeys()) == len(cvData) {
+		if email, err := cv.GetField("email"); err == nil && email == cvData["email"] {
+			fmt.Println("✅ Converting back to multi mints one key per field")
+		} else {
+			fmt.Printf("❌ Field unreadable after converting back: %v\n", err)
+		}
+	} else {
+		fmt.Printf("❌ ConvertMode(multi) failed: %v (%d active keys)\n", err, len(cv.KeyChain().GetAllKeys()))
+	}
+
+	if err := cv.ConvertMode("grouped"); err != nil {
+		fmt.Printf("✅ Unknown mode rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Unknown mode accepted")
+	}
+
+	failed := securecv.NewSecureCV()
+	failed.LoadCV(cvData, "multi")
+	size, current := failed.KeyChain().Size(), failed.KeyChain().GetCurrentKey().KeyID
+	failed.EnableWALStore(&failingWAL{remaining: 2})
+	err = failed.ConvertMode("single")
+	failed.DisableWAL()
+	if err != nil && failed.KeyChain().Size() == size && failed.KeyChain().GetCurrentKey().KeyID == current {
+		fmt.Printf("✅ Failed conversion removed the new key and kept the current key: %v\n", err)
+	} else {
+		fmt.Printf("❌ After failed conversion: err %v, keys %d -> %d\n", err, size, failed.KeyChain().Size())
+	}
+}
+
+// TestLoadCVDataFromReader tests parsing CV data from an io.Reader
+func TestLoadCVDataFromReader() {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: LOAD CV DATA FROM READER")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	r := strings.NewReader(`{"name": "Violet K.", "email": "Violet.tech@Violet.com", "skills": ["Go", "Rust"]}`)
+	cvData, err := fileio.LoadCVDataFromReader(r)
+	expected := map[string]interface{}{
+		"name":   "Violet K.",
+		"email":  "Violet.tech@Violet.com",
+		"skills": []interface{}{"Go", "Rust"},
+	}
+	if err == nil && reflect.DeepEqual(cvData, expected) {
+		fmt.Printf("✅ Parsed %d fields from a reader\n", len(cvData))
+	} else {
+		fmt.Printf("❌ Unexpected parse result %v: %v\n", cvData, err)
+	}
+
+	cv := securecv.NewSecureCV()
+	if err := cv.LoadCV(cvData, "multi"); err == nil {
+		if name, err := cv.GetField("name"); err == nil && name == "Violet K." {
+			fmt.Println("✅ Reader data encrypts and decrypts")
+		} else {
+			fmt.Printf("❌ Field unreadable: %v\n", err)
+		}
+	} else {
+		fmt.Printf("❌ LoadCV failed: %v\n", err)
+	}
+
+	if _, err := fileio.LoadCVDataFromReader(strings.NewReader(`{"name": `)); err != nil {
+		fmt.Printf("✅ Malformed JSON rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Malformed JSON accepted")
+	}
+
+	if _, err := fileio.LoadCVData(filepath.Join(os.TempDir(), "field_cipher_missing.json")); err != nil && strings.Contains(err.Error(), "failed to read file") {
+		fmt.Println("✅ LoadCVData still reports missing files")
+	} else {
+		fmt.Printf("❌ Missing file not reported: %v\n", err)
+	}
+}
+
+// TestEncryptedCVStreaming tests writing an EncryptedCV to an io.Writer and
+// reading it back
+func TestEncryptedCVStreaming(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: ENCRYPTED CV STREAMING")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	cvFile := filepath.Join(dir, "cv.json")
+	cv.SaveEncryptedCV(cvFile)
+	var original models.EncryptedCV
+	fileio.LoadJSON(cvFile, &original)
+
+	var buf bytes.Buffer
+	var writerTo io.WriterTo = &original
+	n, err := writerTo.WriteTo(&buf)
+	if err != nil || n != int64(buf.Len()) {
+		fmt.Printf("❌ WriteTo failed: %v (reported %d of %d bytes)\n", err, n, buf.Len())
+		return
+	}
+	saved, _ := os.ReadFile(cvFile)
+	if bytes.Equal(buf.Bytes(), saved) {
+		fmt.Println("✅ WriteTo produces the same bytes as SaveEncryptedCV")
+	} else {
+		fmt.Println("❌ WriteTo output differs from the saved file")
+	}
+
+	if readBack, err := models.ReadEncryptedCV(&buf); err == nil && reflect.DeepEqual(readBack, &original) {
+		fmt.Printf("✅ ReadEncryptedCV restores an equal struct (%d fields)\n", len(readBack.EncryptedData))
+	} else {
+		fmt.Printf("❌ Read back differs: %v\n", err)
+	}
+
+	if _, err := models.ReadEncryptedCV(strings.NewReader("not json")); err != nil {
+		fmt.Printf("✅ Invalid input rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Invalid input accepted")
+	}
+}
+
+// TestDisplayMasked tests that masked display hides sensitive values and
+// key bytes
+func TestDisplayMasked(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: DISPLAY MASKED")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	cv.SetFieldClassification("email", securecv.ClassConfidential)
+	cv.SetFieldClassification("phone", securecv.ClassSecret)
+
+	var out bytes.Buffer
+	if err := cv.DisplayMasked(&out); err != nil {
+		fmt.Printf("❌ DisplayMasked failed: %v\n", err)
+		return
+	}
+	shown := out.String()
+
+	if strings.Contains(shown, "email: ****") && strings.Contains(shown, "phone: ****") {
+		fmt.Println("✅ Sensitive fields are listed with masked values")
+	} else {
+		fmt.Printf("❌ Sensitive fields not masked:\n%s", shown)
+	}
+	if !strings.Contains(shown, cvData["email"].(string)) && !strings.Contains(shown, cvData["phone"].(string)) {
+		fmt.Println("✅ Sensitive values never appear")
+	} else {
+		fmt.Println("❌ A sensitive value leaked into the display")
+	}
+	if strings.Contains(shown, "name: "+cvData["name"].(string)) {
+		fmt.Println("✅ Public fields show their values")
+	} else {
+		fmt.Printf("❌ Public field missing:\n%s", shown)
+	}
+
+	leaked := false
+	for _, key := range cv.GetAllKeys().Keys {
+		if strings.Contains(shown, key.Key) || strings.Contains(shown, key.KeyHex) {
+			leaked = true
+		}
+	}
+	if !leaked {
+		fmt.Println("✅ No key bytes in the display")
+	} else {
+		fmt.Println("❌ Key bytes printed")
+	}
+}
+
+// TestRotationGrace tests falling back to a field's old key during the
+// grace window after rotation
+func TestRotationGrace(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: ROTATION GRACE")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	dir, err := os.MkdirTemp("", "field_cipher")
+	if err != nil {
+		fmt.Printf("❌ Failed to create temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	cv := securecv.NewSecureCV()
+	cv.SetClock(func() time.Time { return now })
+	cv.SetRotationGrace(time.Hour)
+	cv.LoadCV(cvData, "multi")
+
+	oldFile := filepath.Join(dir, "old.json")
+	cv.SaveEncryptedCV(oldFile)
+	oldKeyID := cv.GetAllKeys().FieldMap["email"]
+	if _, err := cv.RotateFieldKey("email"); err != nil {
+		fmt.Printf("❌ Rotation failed: %v\n", err)
+		return
+	}
+	newFile := filepath.Join(dir, "new.json")
+	cv.SaveEncryptedCV(newFile)
+
+	if keyID, ok := cv.GraceKeyID("email"); ok && keyID == oldKeyID {
+		fmt.Println("✅ Old key tracked alongside the new one during the window")
+	} else {
+		fmt.Printf("❌ Grace key not tracked: %q %v\n", keyID, ok)
+	}
+
+	// Simulate a replica that still holds the pre-rotation ciphertext
+	var oldCV, staleCV models.EncryptedCV
+	fileio.LoadJSON(oldFile, &oldCV)
+	fileio.LoadJSON(newFile, &staleCV)
+	staleCV.EncryptedData["email"] = oldCV.EncryptedData["email"]
+	staleFile := filepath.Join(dir, "stale.json")
+	fileio.SaveJSON(staleFile, &staleCV)
+	if err := cv.LoadEncryptedCV(staleFile); err != nil {
+		fmt.Printf("❌ Failed to load stale CV: %v\n", err)
+		return
+	}
+
+	if email, err := cv.GetField("email"); err == nil && email == cvData["email"] {
+		fmt.Println("✅ Old-key record decrypts via fallback within the window")
+	} else {
+		fmt.Printf("❌ Fallback failed: %v\n", err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, err := cv.GetField("email"); err != nil {
+		fmt.Println("✅ Fallback stops once the window has passed")
+	} else {
+		fmt.Println("❌ Old key still used after the window")
+	}
+	if expired := cv.CleanupRotationGrace(); reflect.DeepEqual(expired, []string{"email"}) {
+		fmt.Println("✅ Cleanup forgets the expired grace key")
+	} else {
+		fmt.Printf("❌ Cleanup returned %v\n", expired)
+	}
+	if _, ok := cv.GraceKeyID("email"); !ok {
+		fmt.Println("✅ No grace key tracked after cleanup")
+	} else {
+		fmt.Println("❌ Grace key still tracked")
+	}
+
+	strict := securecv.NewSecureCV()
+	strict.LoadCV(cvData, "multi")
+	strict.RotateFieldKey("email")
+	if _, ok := strict.GraceKeyID("email"); !ok {
+		fmt.Println("✅ No grace window unless configured")
+	} else {
+		fmt.Println("❌ Grace window opened by default")
+	}
+}
+
+// TestExportCSV tests the CSV audit export
+func TestExportCSV(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: EXPORT CSV")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	cv.RevokeFieldKey("phone")
+
+	var out bytes.Buffer
+	if err := cv.ExportCSV(&out); err != nil {
+		fmt.Printf("❌ ExportCSV failed: %v\n", err)
+		return
+	}
+	exported := out.String()
+	rows, err := csv.NewReader(strings.NewReader(exported)).ReadAll()
+	if err != nil {
+		fmt.Printf("❌ Output is not valid CSV: %v\n", err)
+		return
+	}
+
+	header := []string{"field", "key_id", "type", "nonce_len", "ciphertext_len", "revoked"}
+	if reflect.DeepEqual(rows[0], header) {
+		fmt.Println("✅ Header lists the expected columns")
+	} else {
+		fmt.Printf("❌ Unexpected header %v\n", rows[0])
+	}
+
+	manifest := cv.GetAllKeys()
+	fields := make([]string, 0, len(rows)-1)
+	rowsOK := true
+	for _, row := range rows[1:] {
+		fields = append(fields, row[0])
+		wantRevoked := strconv.FormatBool(row[0] == "phone")
+		expectedLen := len(cvData[row[0]].(string)) + cryptoutils.TagSize
+		if row[1] != manifest.FieldMap[row[0]] || row[2] != "string" || row[3] != "12" || row[4] != strconv.Itoa(expectedLen) || row[5] != wantRevoked {
+			rowsOK = false
+			fmt.Printf("❌ Unexpected row %v\n", row)
+		}
+	}
+	if len(fields) == len(cvData) && sort.StringsAreSorted(fields) {
+		fmt.Printf("✅ One row per field, sorted by name (%d rows)\n", len(fields))
+	} else {
+		fmt.Printf("❌ Rows %v\n", fields)
+	}
+	if rowsOK {
+		fmt.Println("✅ Key IDs, types, lengths and revocation match the CV")
+	}
+
+	leaked := false
+	for _, value := range cvData {
+		if strings.Contains(exported, value.(string)) {
+			leaked = true
+		}
+	}
+	for _, key := range manifest.Keys {
+		if strings.Contains(exported, key.Key) || strings.Contains(exported, key.KeyHex) {
+			leaked = true
+		}
+	}
+	if !leaked {
+		fmt.Println("✅ No plaintext or key bytes in the export")
+	} else {
+		fmt.Println("❌ Export leaks plaintext or key bytes")
+	}
+}
+
+// TestPruneOrphanKeys tests detecting and removing keys that encrypt no
+// fields
+func TestPruneOrphanKeys(cvData map[string]interface{}) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+	fmt.Println("TEST: PRUNE ORPHAN KEYS")
+	fmt.Printf("%s\n", strings.Repeat("=", 70))
+
+	cv := securecv.NewSecureCV()
+	cv.LoadCV(cvData, "multi")
+	if orphans := cv.KeyChain().GetOrphanedKeys(); len(orphans) == 0 {
+		fmt.Println("✅ No orphans after a fresh load")
+	} else {
+		fmt.Printf("❌ %d orphans after a fresh load\n", len(orphans))
+	}
+
+	oldKeyID := cv.GetAllKeys().FieldMap["email"]
+	if _, err := cv.RotateFieldKey("email"); err != nil {
+		fmt.Printf("❌ Rotation failed: %v\n", err)
+		return
+	}
+	orphans := cv.KeyChain().GetOrphanedKeys()
+	if len(orphans) == 1 && orphans[0].KeyID == oldKeyID {
+		fmt.Println("✅ Rotated-off key detected as an orphan")
+	} else {
+		fmt.Printf("❌ Expected old key %s as the only orphan, got %d\n", models.ShortID(oldKeyID, 8), len(orphans))
+	}
+
+	size := cv.KeyChain().Size()
+	if pruned := cv.PruneOrphanKeys(); pruned == 1 && cv.KeyChain().GetNode(oldKeyID) == nil && cv.KeyChain().Size() == size-1 {
+		fmt.Println("✅ Orphan pruned from the chain")
+	} else {
+		fmt.Printf("❌ Pruned %d keys, chain size %d -> %d\n", pruned, size, cv.KeyChain().Size())
+	}
+	if email, err := cv.GetField("email"); err == nil && email == cvData["email"] && len(cv.KeyChain().GetOrphanedKeys()) == 0 {
+		fmt.Println("✅ Fields intact and no orphans left")
+	} else {
+		fmt.Printf("❌ After pruning: %v\n", err)
+	}
+
+	graced := securecv.NewSecureCV()
+	graced.SetRotationGrace(time.Hour)
+	graced.LoadCV(cvData, "multi")
+	graced.RotateFieldKey("email")
+	if pruned := graced.PruneOrphanKeys(); pruned == 0 && len(graced.KeyChain().GetOrphanedKeys()) == 1 {
+		fmt.Println("✅ Keys inside a rotation grace window are kept")
+	} else {
+		fmt.Printf("❌ Grace key pruned (%d removed)\n", pruned)
+	}
+
+	manyFieldsData := make(map[string]interface{})
+	for i := 0; i < 200; i++ {
+		manyFieldsData[fmt.Sprintf("field_%d", i)] = fmt.Sprintf("Value for field %d", i)
+	}
+	concurrent := securecv.NewSecureCV()
+	concurrent.SetParallelism(4)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				concurrent.PruneOrphanKeys()
+			}
+		}
+	}()
+	err := concurrent.LoadCV(manyFieldsData, "multi")
+	close(done)
+	wg.Wait()
+
+	failures := 0
+	for field, value := range manyFieldsData {
+		if decrypted, err := concurrent.GetField(field); err != nil || decrypted != value {
+			failures++
+		}
+	}
+	if err == nil && failures == 0 && concurrent.KeyChain().Size() == len(manyFieldsData) {
+		fmt.Println("✅ Pruning alongside a parallel load keeps every new key")
+	} else {
+		fmt.Printf("❌ Prune during parallel load: err %v, %d fields unreadable\n", err, failures)
+	}
 }
 
 // Demo functions for individual demonstrations
@@ -348,6 +5584,7 @@ func DemoSingleKey() {
 
 	cvData := getSampleData()
 	cv := securecv.NewSecureCV()
+	cv.SetLogger(logging.Stdout{})
 	cv.LoadCV(cvData, "single")
 	cv.DisplayKeys()
 	cv.SaveEncryptedCV("demo_single_cv.json")
@@ -361,6 +5598,7 @@ func DemoMultiKey() {
 
 	cvData := getSampleData()
 	cv := securecv.NewSecureCV()
+	cv.SetLogger(logging.Stdout{})
 	cv.LoadCV(cvData, "multi")
 	cv.DisplayKeys()
 	cv.SaveEncryptedCV("demo_multi_cv.json")
@@ -374,6 +5612,7 @@ func DemoKeyRotation() {
 
 	cvData := getSampleData()
 	cv := securecv.NewSecureCV()
+	cv.SetLogger(logging.Stdout{})
 	cv.LoadCV(cvData, "single")
 
 	emailBefore, _ := cv.GetField("email")
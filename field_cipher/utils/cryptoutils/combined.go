@@ -0,0 +1,39 @@
+package cryptoutils
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// EncryptCombined encrypts plaintext with AES-GCM and returns nonce||
+// ciphertext||tag as a single base64 string, the layout many other libraries
+// expect. The blob carries no type, so values go in and come out as raw
+// bytes; EncryptData's split Nonce/Ciphertext record remains the default.
+func EncryptCombined(plaintext []byte, key []byte, aad []byte) (string, error) {
+	aesgcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := readRandom(nonceReader, NonceSize)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(aesgcm.Seal(nonce, nonce, plaintext, aad)), nil
+}
+
+// DecryptCombined reverses EncryptCombined, splitting the leading 12-byte
+// nonce off the decoded blob; aad must match the value used to encrypt
+func DecryptCombined(combined string, key []byte, aad []byte) ([]byte, error) {
+	aesgcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := base64.StdEncoding.DecodeString(combined)
+	if err != nil {
+		return nil, fmt.Errorf("invalid combined ciphertext: %v", err)
+	}
+	if len(blob) < NonceSize+TagSize {
+		return nil, fmt.Errorf("combined ciphertext too short: %d bytes, need at least %d for nonce and tag", len(blob), NonceSize+TagSize)
+	}
+	return aesgcm.Open(nil, blob[:NonceSize], blob[NonceSize:], aad)
+}
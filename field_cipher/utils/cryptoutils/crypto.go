@@ -2,17 +2,69 @@ package cryptoutils
 
 import (
 	"field_cipher/models"
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/pbkdf2"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
+	"strconv"
 )
 
-// EncryptData encrypts data with AES-GCM
-func EncryptData(plaintext interface{}, key []byte) (*models.EncryptedData, error) {
+// NonceSize is the AES-GCM nonce length used for all field encryption
+const NonceSize = 12
+
+// TagSize is the AES-GCM authentication tag length appended to ciphertexts
+const TagSize = 16
+
+// nonceReader is the randomness source for EncryptData nonces
+var nonceReader io.Reader = rand.Reader
+
+// keyReader is the randomness source for GenerateRandomBytes and everything
+// built on it: keys, key IDs and salts
+var keyReader io.Reader = rand.Reader
+
+// SetRandReader replaces the source of random nonces, for tests that need a
+// deterministic or faulty RNG; nil restores crypto/rand. Not safe to call
+// while encryptions are running.
+func SetRandReader(r io.Reader) {
+	if r == nil {
+		r = rand.Reader
+	}
+	nonceReader = r
+}
+
+// SetKeyRandReader replaces the source behind GenerateRandomBytes and
+// GenerateRandomBytesErr, for tests that need a faulty RNG; nil restores
+// crypto/rand. Not safe to call while keys are being generated.
+func SetKeyRandReader(r io.Reader) {
+	if r == nil {
+		r = rand.Reader
+	}
+	keyReader = r
+}
+
+// EncryptData encrypts data with AES-GCM, authenticating aad alongside it
+func EncryptData(plaintext interface{}, key []byte, aad []byte) (*models.EncryptedData, error) {
+	nonce, err := readRandom(nonceReader, NonceSize)
+	if err != nil {
+		return nil, err
+	}
+	return EncryptDataWithNonce(plaintext, key, nonce, aad)
+}
+
+// EncryptDataWithNonce encrypts data with AES-GCM under a caller-supplied
+// nonce. The caller is responsible for never reusing a nonce with the same key.
+func EncryptDataWithNonce(plaintext interface{}, key []byte, nonce []byte, aad []byte) (*models.EncryptedData, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
@@ -23,25 +75,16 @@ func EncryptData(plaintext interface{}, key []byte) (*models.EncryptedData, erro
 		return nil, err
 	}
 
-	nonce := make([]byte, aesgcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, err
+	if len(nonce) != aesgcm.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce size: %d bytes (must be %d)", len(nonce), aesgcm.NonceSize())
 	}
 
-	// Serialize to JSON
-	var text string
-	switch v := plaintext.(type) {
-	case string:
-		text = v
-	default:
-		jsonBytes, err := json.Marshal(plaintext)
-		if err != nil {
-			return nil, err
-		}
-		text = string(jsonBytes)
+	text, err := SerializeValue(plaintext)
+	if err != nil {
+		return nil, err
 	}
 
-	ciphertext := aesgcm.Seal(nil, nonce, []byte(text), nil)
+	ciphertext := aesgcm.Seal(nil, nonce, text, aad)
 
 	return &models.EncryptedData{
 		Nonce:      base64.StdEncoding.EncodeToString(nonce),
@@ -50,8 +93,91 @@ func EncryptData(plaintext interface{}, key []byte) (*models.EncryptedData, erro
 	}, nil
 }
 
-// DecryptData decrypts data with AES-GCM
-func DecryptData(encrypted *models.EncryptedData, key []byte) (interface{}, error) {
+// SerializeValue returns the bytes that get encrypted for a value: strings
+// as-is, everything else as CanonicalJSON
+func SerializeValue(value interface{}) ([]byte, error) {
+	if text, ok := value.(string); ok {
+		return []byte(text), nil
+	}
+	return CanonicalJSON(value)
+}
+
+// CanonicalJSON encodes value as compact JSON with object keys sorted at
+// every depth, so equal values give identical bytes whether they came from
+// maps, structs or pre-encoded JSON with its own key order and whitespace.
+// Numbers keep their literal form.
+func CanonicalJSON(value interface{}) ([]byte, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
+}
+
+// FieldAAD builds the associated data for a field: the field name, a NUL
+// separator, then any caller-supplied context
+func FieldAAD(field string, aad []byte) []byte {
+	bound := make([]byte, 0, len(field)+1+len(aad))
+	bound = append(bound, field...)
+	bound = append(bound, 0)
+	return append(bound, aad...)
+}
+
+// MetadataAAD appends a record's metadata to aad, length-prefixed in sorted
+// key order, so labels cannot be changed after encryption. Without metadata
+// aad is returned unchanged.
+func MetadataAAD(aad []byte, metadata map[string]string) []byte {
+	if len(metadata) == 0 {
+		return aad
+	}
+
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	bound := append([]byte{}, aad...)
+	bound = append(bound, "\x00metadata"...)
+	for _, k := range keys {
+		bound = binary.BigEndian.AppendUint32(bound, uint32(len(k)))
+		bound = append(bound, k...)
+		bound = binary.BigEndian.AppendUint32(bound, uint32(len(metadata[k])))
+		bound = append(bound, metadata[k]...)
+	}
+	return bound
+}
+
+// DecryptData decrypts data with AES-GCM; aad must match the value used to
+// encrypt. Any metadata on the record is bound in via MetadataAAD.
+func DecryptData(encrypted *models.EncryptedData, key []byte, aad []byte) (interface{}, error) {
+	return decryptData(encrypted, key, aad, false)
+}
+
+// DecryptDataUseNumber is DecryptData with numbers nested in maps and slices
+// decoded as json.Number instead of float64, so large integers keep every
+// digit
+func DecryptDataUseNumber(encrypted *models.EncryptedData, key []byte, aad []byte) (interface{}, error) {
+	return decryptData(encrypted, key, aad, true)
+}
+
+// decryptData implements DecryptData and DecryptDataUseNumber
+func decryptData(encrypted *models.EncryptedData, key []byte, aad []byte, useNumber bool) (interface{}, error) {
+	aad = MetadataAAD(aad, encrypted.Metadata)
+	switch encrypted.Type {
+	case "partial":
+		return decryptRegions(encrypted, key, aad)
+	case "stream":
+		return decryptStreamData(encrypted, key, aad)
+	}
+
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
@@ -66,61 +192,190 @@ func DecryptData(encrypted *models.EncryptedData, key []byte) (interface{}, erro
 	if err != nil {
 		return nil, err
 	}
+	if len(nonce) != aesgcm.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length: %d bytes, expected %d", len(nonce), aesgcm.NonceSize())
+	}
 
 	ciphertext, err := base64.StdEncoding.DecodeString(encrypted.Ciphertext)
 	if err != nil {
 		return nil, err
 	}
+	if len(ciphertext) < aesgcm.Overhead() {
+		return nil, fmt.Errorf("ciphertext too short: %d bytes, shorter than the %d-byte tag", len(ciphertext), aesgcm.Overhead())
+	}
 
-	plaintext, err := aesgcm.Open(nil, nonce, ciphertext, nil)
+	plaintext, err := aesgcm.Open(nil, nonce, ciphertext, aad)
 	if err != nil {
 		return nil, err
 	}
 
-	if encrypted.Type == "map" || encrypted.Type == "slice" {
-		var result interface{}
-		if err := json.Unmarshal(plaintext, &result); err != nil {
-			return nil, err
+	switch encrypted.Type {
+	case "string":
+		return string(plaintext), nil
+	case "":
+		return decodeUntyped(plaintext, useNumber), nil
+	case "number":
+		return decodeNumber(plaintext)
+	case "null":
+		if string(plaintext) != "null" {
+			return nil, fmt.Errorf("invalid null value")
+		}
+		return nil, nil
+	}
+
+	// Everything else was JSON-serialized on the way in
+	return decodeJSON(plaintext, useNumber)
+}
+
+// decodeJSON unmarshals plaintext into a generic value, with numbers as
+// json.Number when useNumber is set
+func decodeJSON(plaintext []byte, useNumber bool) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(plaintext))
+	if useNumber {
+		decoder.UseNumber()
+	}
+	var result interface{}
+	if err := decoder.Decode(&result); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid data after top-level JSON value")
+	}
+	return result, nil
+}
+
+// decodeUntyped makes a best effort with legacy records that have no Type:
+// a JSON object or array comes back as a map or slice, anything else as the
+// string it was
+func decodeUntyped(plaintext []byte, useNumber bool) interface{} {
+	if result, err := decodeJSON(plaintext, useNumber); err == nil {
+		switch result.(type) {
+		case map[string]interface{}, []interface{}:
+			return result
 		}
-		return result, nil
 	}
+	return string(plaintext)
+}
+
+// decodeNumber restores a "number" field. Integral values come back as int
+// so 42 round-trips as 42 rather than 42.0; anything else is a float64.
+// Numbers nested inside maps and slices decode as float64, or json.Number
+// with DecryptDataUseNumber.
+func decodeNumber(plaintext []byte) (interface{}, error) {
+	var n json.Number
+	if err := json.Unmarshal(plaintext, &n); err != nil {
+		return nil, err
+	}
+	if i, err := strconv.ParseInt(n.String(), 10, 0); err == nil {
+		return int(i), nil
+	}
+	return n.Float64()
+}
+
+// DeriveNonce derives a GCM nonce as HKDF(key, counter). Nonces are unique
+// per key for as long as the counter is never reused.
+func DeriveNonce(key []byte, counter uint64) ([]byte, error) {
+	info := make([]byte, 8)
+	binary.BigEndian.PutUint64(info, counter)
+	return hkdf.Key(sha256.New, key, nil, "field_cipher nonce "+string(info), NonceSize)
+}
 
-	return string(plaintext), nil
+// DeriveSubkey derives a 256-bit key from root via HKDF-SHA256, with info
+// separating independent keys derived from the same root
+func DeriveSubkey(root []byte, info string) []byte {
+	key, err := hkdf.Key(sha256.New, root, nil, info, 32)
+	if err != nil {
+		panic(err) // only possible for invalid output lengths
+	}
+	return key
 }
 
-// GenerateRandomBytes generates cryptographically secure random bytes
+// GenerateRandomBytes generates cryptographically secure random bytes,
+// panicking if the RNG fails; servers should prefer GenerateRandomBytesErr
 func GenerateRandomBytes(n int) []byte {
-	b := make([]byte, n)
-	if _, err := rand.Read(b); err != nil {
+	b, err := GenerateRandomBytesErr(n)
+	if err != nil {
 		panic(err)
 	}
 	return b
 }
 
-// GenerateRandomHex generates a random hexadecimal string
-func GenerateRandomHex(n int) string {
-	const letters = "0123456789abcdef"
+// GenerateRandomBytesErr generates cryptographically secure random bytes,
+// returning an error instead of panicking if the RNG fails
+func GenerateRandomBytesErr(n int) ([]byte, error) {
+	return readRandom(keyReader, n)
+}
+
+// readRandom reads exactly n bytes from r
+func readRandom(r io.Reader, n int) ([]byte, error) {
 	b := make([]byte, n)
-	for i := range b {
-		b[i] = letters[GenerateRandomBytes(1)[0]%byte(len(letters))]
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, fmt.Errorf("failed to read %d random bytes: %v", n, err)
+	}
+	return b, nil
+}
+
+// GenerateRandomHex generates a random lowercase hexadecimal string of n
+// characters from a single read of ceil(n/2) random bytes
+func GenerateRandomHex(n int) string {
+	s, err := GenerateRandomHexErr(n)
+	if err != nil {
+		panic(err)
 	}
-	return string(b)
+	return s
+}
+
+// GenerateRandomHexErr is GenerateRandomHex returning an error instead of
+// panicking if the RNG fails
+func GenerateRandomHexErr(n int) (string, error) {
+	b, err := GenerateRandomBytesErr((n + 1) / 2)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b)[:n], nil
 }
 
 // getTypeName returns the type name of the value
 func getTypeName(v interface{}) string {
 	switch v.(type) {
+	case nil:
+		return "null"
 	case string:
 		return "string"
 	case map[string]interface{}:
 		return "map"
 	case []interface{}:
 		return "slice"
+	case bool:
+		return "bool"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		return "number"
 	default:
 		return fmt.Sprintf("%T", v)
 	}
 }
 
+// KeyFingerprint returns a short fingerprint of key material, the first 16
+// bytes of its SHA-256 in hex, so holders of the same key bytes can confirm a
+// match regardless of key ID
+func KeyFingerprint(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:16])
+}
+
+// ConstantTimeKeyEqual compares key bytes or fingerprints in time that
+// depends only on their lengths, unlike bytes.Equal which stops at the first
+// difference
+func ConstantTimeKeyEqual(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// Zeroize overwrites b with zeros. This is best effort: Go may already have
+// copied the bytes elsewhere (GC moves, string conversions, spilled registers).
+func Zeroize(b []byte) {
+	clear(b)
+}
+
 // ValidateKey checks if a key is valid for AES encryption
 func ValidateKey(key []byte) error {
 	switch len(key) {
@@ -135,9 +390,17 @@ func ValidateKey(key []byte) error {
 func GenerateAESKey(size int) ([]byte, error) {
 	switch size {
 	case 128, 192, 256:
-		bytes := size / 8
-		return GenerateRandomBytes(bytes), nil
+		return GenerateRandomBytesErr(size / 8)
 	default:
 		return nil, fmt.Errorf("invalid AES key size: %d (must be 128, 192, or 256)", size)
 	}
 }
+
+// DeriveKeyFromPassphrase derives a 256-bit AES key from a passphrase using
+// PBKDF2-HMAC-SHA256
+func DeriveKeyFromPassphrase(passphrase string, salt []byte, iterations int) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase is empty")
+	}
+	return pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+}
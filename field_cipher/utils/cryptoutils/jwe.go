@@ -0,0 +1,140 @@
+package cryptoutils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jweHeader is the protected header of a compact JWE
+type jweHeader struct {
+	Alg string `json:"alg"`
+	Enc string `json:"enc"`
+	Cty string `json:"cty,omitempty"`
+}
+
+// EncryptJWE produces a compact JWE (RSA-OAEP key wrapping, A256GCM content
+// encryption) that standard JOSE libraries can decrypt. contentType is
+// placed in the "cty" header when non-empty.
+func EncryptJWE(plaintext []byte, contentType string, pub *rsa.PublicKey) (string, error) {
+	headerJSON, err := json.Marshal(jweHeader{Alg: "RSA-OAEP", Enc: "A256GCM", Cty: contentType})
+	if err != nil {
+		return "", err
+	}
+	header := base64.RawURLEncoding.EncodeToString(headerJSON)
+
+	cek, err := GenerateRandomBytesErr(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate content key: %v", err)
+	}
+	encryptedKey, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, pub, cek, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap content key: %v", err)
+	}
+
+	aesgcm, err := newGCM(cek)
+	if err != nil {
+		return "", err
+	}
+	iv, err := GenerateRandomBytesErr(aesgcm.NonceSize())
+	if err != nil {
+		return "", fmt.Errorf("failed to generate IV: %v", err)
+	}
+
+	// The ASCII header is the AAD per RFC 7516
+	sealed := aesgcm.Seal(nil, iv, plaintext, []byte(header))
+	ciphertext, tag := sealed[:len(sealed)-TagSize], sealed[len(sealed)-TagSize:]
+
+	return strings.Join([]string{
+		header,
+		base64.RawURLEncoding.EncodeToString(encryptedKey),
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(ciphertext),
+		base64.RawURLEncoding.EncodeToString(tag),
+	}, "."), nil
+}
+
+// DecryptJWE decrypts a compact JWE produced with RSA-OAEP and A256GCM,
+// returning the plaintext and the "cty" header value
+func DecryptJWE(token string, priv *rsa.PrivateKey) ([]byte, string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return nil, "", fmt.Errorf("invalid compact JWE: expected 5 parts, got %d", len(parts))
+	}
+
+	decoded := make([][]byte, 5)
+	for i, part := range parts {
+		b, err := base64.RawURLEncoding.DecodeString(part)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid compact JWE part %d: %v", i, err)
+		}
+		decoded[i] = b
+	}
+
+	var header jweHeader
+	if err := json.Unmarshal(decoded[0], &header); err != nil {
+		return nil, "", fmt.Errorf("invalid JWE header: %v", err)
+	}
+	if header.Alg != "RSA-OAEP" || header.Enc != "A256GCM" {
+		return nil, "", fmt.Errorf("unsupported JWE algorithms: alg=%s enc=%s", header.Alg, header.Enc)
+	}
+
+	cek, err := rsa.DecryptOAEP(sha1.New(), rand.Reader, priv, decoded[1], nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to unwrap content key: %v", err)
+	}
+	if len(cek) != 32 {
+		return nil, "", fmt.Errorf("invalid content key size: %d bytes", len(cek))
+	}
+
+	aesgcm, err := newGCM(cek)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(decoded[2]) != aesgcm.NonceSize() {
+		return nil, "", fmt.Errorf("invalid JWE IV length: %d bytes", len(decoded[2]))
+	}
+
+	plaintext, err := aesgcm.Open(nil, decoded[2], append(decoded[3], decoded[4]...), []byte(parts[0]))
+	if err != nil {
+		return nil, "", err
+	}
+	return plaintext, header.Cty, nil
+}
+
+// newGCM creates an AES-GCM AEAD for key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// JWK is a symmetric ("oct") JSON Web Key per RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	K   string `json:"k"`
+	Alg string `json:"alg"`
+}
+
+// ToJWK encodes a key as an oct JWK; k is base64url without padding and alg
+// follows the key size (A128GCM, A192GCM or A256GCM)
+func ToJWK(keyID string, key []byte) ([]byte, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+	return json.Marshal(JWK{
+		Kty: "oct",
+		Kid: keyID,
+		K:   base64.RawURLEncoding.EncodeToString(key),
+		Alg: fmt.Sprintf("A%dGCM", len(key)*8),
+	})
+}
@@ -0,0 +1,39 @@
+package cryptoutils
+
+import (
+	"encoding/pem"
+	"fmt"
+)
+
+// keyPEMType is the PEM block type for field cipher keys
+const keyPEMType = "FIELD CIPHER KEY"
+
+// EncodeKeyPEM wraps a key in a text-safe PEM block with a KeyID header
+func EncodeKeyPEM(keyID string, key []byte) string {
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:    keyPEMType,
+		Headers: map[string]string{"KeyID": keyID},
+		Bytes:   key,
+	}))
+}
+
+// DecodeKeyPEM decodes the first key block in data and returns the rest, so
+// concatenated blocks can be read in a loop until rest is empty
+func DecodeKeyPEM(data string) (string, []byte, string, error) {
+	block, rest := pem.Decode([]byte(data))
+	if block == nil {
+		return "", nil, data, fmt.Errorf("no PEM block found")
+	}
+	if block.Type != keyPEMType {
+		return "", nil, string(rest), fmt.Errorf("unexpected PEM block type %q", block.Type)
+	}
+
+	keyID := block.Headers["KeyID"]
+	if keyID == "" {
+		return "", nil, string(rest), fmt.Errorf("PEM block missing KeyID header")
+	}
+	if err := ValidateKey(block.Bytes); err != nil {
+		return "", nil, string(rest), fmt.Errorf("invalid key %s: %v", keyID, err)
+	}
+	return keyID, block.Bytes, string(rest), nil
+}
@@ -0,0 +1,83 @@
+package cryptoutils
+
+import (
+	"field_cipher/models"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// EncryptRegions encrypts only the [start, end) byte ranges of text given in
+// regions, keeping the rest as cleartext. Regions must be sorted and must not
+// overlap. The cleartext parts are authenticated along with each region.
+func EncryptRegions(text string, regions [][]int, key []byte, aad []byte) (*models.EncryptedData, error) {
+	segments := make([]*models.Segment, 0, 2*len(regions)+1)
+	plaintexts := make(map[int]string, len(regions))
+	pos := 0
+	for _, region := range regions {
+		if len(region) != 2 || region[0] < pos || region[1] < region[0] || region[1] > len(text) {
+			return nil, fmt.Errorf("invalid region %v", region)
+		}
+		if region[0] > pos {
+			segments = append(segments, &models.Segment{Clear: text[pos:region[0]]})
+		}
+		if region[1] > region[0] {
+			// Sealed below, once the full layout is known
+			plaintexts[len(segments)] = text[region[0]:region[1]]
+			segments = append(segments, &models.Segment{Sealed: &models.EncryptedData{}})
+		}
+		pos = region[1]
+	}
+	if pos < len(text) {
+		segments = append(segments, &models.Segment{Clear: text[pos:]})
+	}
+
+	for i, plaintext := range plaintexts {
+		sealed, err := EncryptData(plaintext, key, segmentAAD(aad, segments, i))
+		if err != nil {
+			return nil, err
+		}
+		segments[i].Sealed = sealed
+	}
+
+	return &models.EncryptedData{Type: "partial", Segments: segments}, nil
+}
+
+// decryptRegions reassembles a partially encrypted string
+func decryptRegions(encrypted *models.EncryptedData, key []byte, aad []byte) (string, error) {
+	var text strings.Builder
+	for i, segment := range encrypted.Segments {
+		if segment.Sealed == nil {
+			text.WriteString(segment.Clear)
+			continue
+		}
+		region, err := DecryptData(segment.Sealed, key, segmentAAD(aad, encrypted.Segments, i))
+		if err != nil {
+			return "", fmt.Errorf("region %d: %v", i, err)
+		}
+		regionText, ok := region.(string)
+		if !ok {
+			return "", fmt.Errorf("region %d: unexpected type %T", i, region)
+		}
+		text.WriteString(regionText)
+	}
+	return text.String(), nil
+}
+
+// segmentAAD binds a region to its position and to the cleartext layout, so
+// neither regions nor cleartext parts can be swapped or edited
+func segmentAAD(aad []byte, segments []*models.Segment, index int) []byte {
+	layout := make([]*string, len(segments))
+	for i, segment := range segments {
+		if segment.Sealed == nil {
+			clear := segment.Clear
+			layout[i] = &clear
+		}
+	}
+	layoutJSON, _ := json.Marshal(layout)
+
+	out := append([]byte{}, aad...)
+	out = binary.BigEndian.AppendUint32(append(out, 0), uint32(index))
+	return append(append(out, 0), layoutJSON...)
+}
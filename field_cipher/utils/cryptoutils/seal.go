@@ -0,0 +1,43 @@
+package cryptoutils
+
+import (
+	"fmt"
+	"io"
+)
+
+// SealBytes encrypts raw bytes with AES-GCM and returns nonce || ciphertext
+func SealBytes(plaintext, key, aad []byte) ([]byte, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+	aesgcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, NonceSize, NonceSize+len(plaintext)+TagSize)
+	if _, err := io.ReadFull(nonceReader, nonce); err != nil {
+		return nil, err
+	}
+	return aesgcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// OpenBytes reverses SealBytes
+func OpenBytes(sealed, key, aad []byte) ([]byte, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+	if len(sealed) < NonceSize+TagSize {
+		return nil, fmt.Errorf("sealed data too short: %d bytes", len(sealed))
+	}
+	aesgcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aesgcm.Open(nil, sealed[:NonceSize], sealed[NonceSize:], aad)
+	if err != nil {
+		return nil, fmt.Errorf("decryption failed (wrong key or tampered data): %v", err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,192 @@
+package cryptoutils
+
+import (
+	"field_cipher/models"
+	"bytes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Stream format: a 7-byte random nonce prefix, then frames of
+// [1-byte final flag][4-byte big-endian length][sealed chunk]. Each chunk's
+// nonce is prefix || counter || final flag, so reordered, dropped or
+// truncated frames fail authentication.
+const (
+	streamChunkSize   = 64 * 1024
+	streamPrefixSize  = 7
+	streamFrameHeader = 5
+)
+
+// ErrStreamTruncated is returned when a stream ends before its final chunk
+var ErrStreamTruncated = errors.New("stream authentication failed: truncated before final chunk")
+
+// EncryptStream encrypts r to w in chunks with AES-GCM, holding at most two
+// chunks in memory
+func EncryptStream(r io.Reader, w io.Writer, key []byte) error {
+	aesgcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+
+	prefix, err := GenerateRandomBytesErr(streamPrefixSize)
+	if err != nil {
+		return fmt.Errorf("failed to generate stream prefix: %v", err)
+	}
+	if _, err := w.Write(prefix); err != nil {
+		return err
+	}
+
+	current := make([]byte, streamChunkSize)
+	next := make([]byte, streamChunkSize)
+	n, err := io.ReadFull(r, current)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+
+	for counter := uint32(0); ; counter++ {
+		// A chunk is final when the reader cannot fill the one after it
+		final := n < streamChunkSize
+		var m int
+		if !final {
+			m, err = io.ReadFull(r, next)
+			if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+				return err
+			}
+			final = m == 0
+		}
+		if counter == ^uint32(0) && !final {
+			return fmt.Errorf("stream too long")
+		}
+
+		if err := writeStreamFrame(w, aesgcm, prefix, counter, current[:n], final); err != nil {
+			return err
+		}
+		if final {
+			return nil
+		}
+		current, next, n = next, current, m
+	}
+}
+
+// writeStreamFrame seals and writes one chunk
+func writeStreamFrame(w io.Writer, aesgcm cipher.AEAD, prefix []byte, counter uint32, chunk []byte, final bool) error {
+	sealed := aesgcm.Seal(nil, streamNonce(prefix, counter, final), chunk, nil)
+
+	header := make([]byte, streamFrameHeader)
+	if final {
+		header[0] = 1
+	}
+	binary.BigEndian.PutUint32(header[1:], uint32(len(sealed)))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(sealed)
+	return err
+}
+
+// DecryptStream decrypts a stream produced by EncryptStream from r to w.
+// Plaintext is written chunk by chunk as each authenticates, so on error w
+// may hold a verified prefix that callers must discard.
+func DecryptStream(r io.Reader, w io.Writer, key []byte) error {
+	aesgcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+
+	prefix := make([]byte, streamPrefixSize)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return ErrStreamTruncated
+	}
+
+	header := make([]byte, streamFrameHeader)
+	sealed := make([]byte, streamChunkSize+TagSize)
+	for counter := uint32(0); ; counter++ {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return ErrStreamTruncated
+			}
+			return err
+		}
+
+		final := header[0] == 1
+		size := binary.BigEndian.Uint32(header[1:])
+		if header[0] > 1 || size < TagSize || size > uint32(len(sealed)) {
+			return fmt.Errorf("stream authentication failed: malformed frame %d", counter)
+		}
+		if _, err := io.ReadFull(r, sealed[:size]); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return ErrStreamTruncated
+			}
+			return err
+		}
+
+		chunk, err := aesgcm.Open(nil, streamNonce(prefix, counter, final), sealed[:size], nil)
+		if err != nil {
+			return fmt.Errorf("stream authentication failed at chunk %d: %v", counter, err)
+		}
+		if _, err := w.Write(chunk); err != nil {
+			return err
+		}
+
+		if final {
+			if n, _ := r.Read(make([]byte, 1)); n > 0 {
+				return fmt.Errorf("stream authentication failed: data after final chunk")
+			}
+			return nil
+		}
+	}
+}
+
+// streamNonce builds the nonce for one chunk
+func streamNonce(prefix []byte, counter uint32, final bool) []byte {
+	nonce := make([]byte, NonceSize)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[streamPrefixSize:], counter)
+	if final {
+		nonce[NonceSize-1] = 1
+	}
+	return nonce
+}
+
+// EncryptStreamData encrypts a reader into a "stream" EncryptedData record.
+// The stream key is derived from key and aad so the record stays bound to
+// its context like any other field.
+func EncryptStreamData(r io.Reader, key []byte, aad []byte) (*models.EncryptedData, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := EncryptStream(r, &buf, streamKey(key, aad)); err != nil {
+		return nil, err
+	}
+
+	return &models.EncryptedData{
+		Ciphertext: base64.StdEncoding.EncodeToString(buf.Bytes()),
+		Type:       "stream",
+	}, nil
+}
+
+// decryptStreamData reverses EncryptStreamData, returning the value as a string
+func decryptStreamData(encrypted *models.EncryptedData, key []byte, aad []byte) (interface{}, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(encrypted.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	var plaintext bytes.Buffer
+	if err := DecryptStream(bytes.NewReader(ciphertext), &plaintext, streamKey(key, aad)); err != nil {
+		return nil, err
+	}
+	return plaintext.String(), nil
+}
+
+// streamKey derives the per-context stream key
+func streamKey(key []byte, aad []byte) []byte {
+	return DeriveSubkey(key, "field_cipher stream "+string(aad))
+}
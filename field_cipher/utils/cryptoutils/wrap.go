@@ -0,0 +1,99 @@
+package cryptoutils
+
+import (
+	"field_cipher/models"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// keyWrapAAD binds wrapped keys to their purpose so a wrapped key cannot be
+// passed off as an ordinary field ciphertext
+var keyWrapAAD = []byte("field_cipher key wrap")
+
+// WrapKey encrypts a data encryption key (DEK) under a key encryption key
+// (KEK) with AES-GCM
+func WrapKey(dek, kek []byte) (*models.EncryptedData, error) {
+	if err := ValidateKey(dek); err != nil {
+		return nil, fmt.Errorf("invalid data key: %v", err)
+	}
+	if err := ValidateKey(kek); err != nil {
+		return nil, fmt.Errorf("invalid master key: %v", err)
+	}
+
+	aesgcm, err := newGCM(kek)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, NonceSize)
+	if _, err := io.ReadFull(nonceReader, nonce); err != nil {
+		return nil, err
+	}
+
+	return &models.EncryptedData{
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(aesgcm.Seal(nil, nonce, dek, keyWrapAAD)),
+		Type:       "wrapped_key",
+	}, nil
+}
+
+// UnwrapKey reverses WrapKey; it fails if kek is not the key that wrapped it
+func UnwrapKey(wrapped *models.EncryptedData, kek []byte) ([]byte, error) {
+	if wrapped == nil || wrapped.Type != "wrapped_key" {
+		return nil, fmt.Errorf("not a wrapped key")
+	}
+	if err := ValidateKey(kek); err != nil {
+		return nil, fmt.Errorf("invalid master key: %v", err)
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(wrapped.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(wrapped.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != NonceSize {
+		return nil, fmt.Errorf("invalid nonce size: %d bytes (must be %d)", len(nonce), NonceSize)
+	}
+
+	aesgcm, err := newGCM(kek)
+	if err != nil {
+		return nil, err
+	}
+	dek, err := aesgcm.Open(nil, nonce, ciphertext, keyWrapAAD)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap failed (wrong master key or tampered data): %v", err)
+	}
+	return dek, nil
+}
+
+// WrapForRecipients wraps dek once under each recipient's KEK, keyed by
+// recipient ID, so each recipient can unwrap it with UnwrapKey and their own
+// KEK alone. Nothing is returned unless every recipient's wrap succeeds.
+func WrapForRecipients(dek []byte, recipientKEKs map[string][]byte) (map[string]*models.EncryptedData, error) {
+	if len(recipientKEKs) == 0 {
+		return nil, fmt.Errorf("no recipients")
+	}
+
+	ids := make([]string, 0, len(recipientKEKs))
+	for id := range recipientKEKs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	wrapped := make(map[string]*models.EncryptedData, len(ids))
+	for _, id := range ids {
+		if id == "" {
+			return nil, fmt.Errorf("recipient ID is empty")
+		}
+		w, err := WrapKey(dek, recipientKEKs[id])
+		if err != nil {
+			return nil, fmt.Errorf("failed to wrap key for recipient %s: %v", id, err)
+		}
+		wrapped[id] = w
+	}
+	return wrapped, nil
+}
@@ -3,7 +3,11 @@ package fileio
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 )
 
 // SaveJSON saves data as JSON to file
@@ -13,14 +17,49 @@ func SaveJSON(filename string, data interface{}) error {
 		return fmt.Errorf("failed to marshal JSON: %v", err)
 	}
 
-	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
+	if err := writeFileAtomic(filename, jsonData, 0644); err != nil {
 		return fmt.Errorf("failed to write file %s: %v", filename, err)
 	}
 
-	fmt.Printf("Saved data to %s\n", filename)
 	return nil
 }
 
+// SaveBytes writes raw bytes to file atomically
+func SaveBytes(filename string, data []byte) error {
+	if err := writeFileAtomic(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %v", filename, err)
+	}
+
+	return nil
+}
+
+// writeFileAtomic writes data to a uniquely named temp file in the target's
+// directory and renames it into place, so readers never see a partial file
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
 // LoadJSON loads JSON data from file
 func LoadJSON(filename string, result interface{}) error {
 	data, err := os.ReadFile(filename)
@@ -32,7 +71,6 @@ func LoadJSON(filename string, result interface{}) error {
 		return fmt.Errorf("failed to parse JSON from %s: %v", filename, err)
 	}
 
-	fmt.Printf("Loaded data from %s\n", filename)
 	return nil
 }
 
@@ -59,10 +97,68 @@ func CreateBackup(filename string) error {
 
 // LoadCVData loads CV data from JSON file
 func LoadCVData(filename string) (map[string]interface{}, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %v", filename, err)
+	}
+	defer f.Close()
+
+	cvData, err := LoadCVDataFromReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load CV data from %s: %v", filename, err)
+	}
+	return cvData, nil
+}
+
+// LoadCVDataFromReader loads CV data from JSON read from r, such as stdin,
+// an HTTP body or an embedded asset
+func LoadCVDataFromReader(r io.Reader) (map[string]interface{}, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CV data: %v", err)
+	}
+
 	var cvData map[string]interface{}
-	if err := LoadJSON(filename, &cvData); err != nil {
+	if err := json.Unmarshal(data, &cvData); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+	return cvData, nil
+}
+
+// LoadCVDataValidated loads CV data and fails if any required field is
+// missing or any field is null, naming every offending field
+func LoadCVDataValidated(filename string, requiredFields []string) (map[string]interface{}, error) {
+	cvData, err := LoadCVData(filename)
+	if err != nil {
 		return nil, err
 	}
+	if cvData == nil {
+		return nil, fmt.Errorf("invalid CV data in %s: not a JSON object", filename)
+	}
+
+	var missing, null []string
+	for _, field := range requiredFields {
+		if _, exists := cvData[field]; !exists {
+			missing = append(missing, field)
+		}
+	}
+	for field, value := range cvData {
+		if value == nil {
+			null = append(null, field)
+		}
+	}
+	sort.Strings(null)
+
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "missing required fields: "+strings.Join(missing, ", "))
+	}
+	if len(null) > 0 {
+		problems = append(problems, "null fields: "+strings.Join(null, ", "))
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid CV data in %s: %s", filename, strings.Join(problems, "; "))
+	}
 	return cvData, nil
 }
 
@@ -82,11 +178,49 @@ func ListFiles(dirname, extension string) ([]string, error) {
 	for _, file := range files {
 		if !file.IsDir() {
 			name := file.Name()
-			if extension == "" || (len(name) > len(extension) && name[len(name)-len(extension):] == extension) {
+			if matchesExtension(name, extension) {
 				result = append(result, name)
 			}
 		}
 	}
 
 	return result, nil
+}
+
+// ListFilesRecursive lists files with a specific extension in a directory
+// and all its subdirectories, as paths relative to dirname
+func ListFilesRecursive(dirname, extension string) ([]string, error) {
+	var result []string
+	err := filepath.WalkDir(dirname, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !matchesExtension(entry.Name(), extension) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dirname, path)
+		if err != nil {
+			return err
+		}
+		result = append(result, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// matchesExtension reports whether a file name has the given extension, with
+// or without its leading dot; an empty extension matches every file
+func matchesExtension(name, extension string) bool {
+	if extension == "" {
+		return true
+	}
+	if !strings.HasPrefix(extension, ".") {
+		extension = "." + extension
+	}
+	return len(name) > len(extension) && strings.HasSuffix(name, extension)
 }
\ No newline at end of file
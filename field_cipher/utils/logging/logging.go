@@ -0,0 +1,30 @@
+package logging
+
+import "fmt"
+
+// Logger receives the library's progress messages; *log.Logger satisfies it
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Nop discards every message; it is the default logger
+type Nop struct{}
+
+// Printf implements Logger
+func (Nop) Printf(format string, v ...interface{}) {}
+
+// Stdout prints each message on its own line to standard output
+type Stdout struct{}
+
+// Printf implements Logger
+func (Stdout) Printf(format string, v ...interface{}) {
+	fmt.Printf(format+"\n", v...)
+}
+
+// OrNop returns logger, or Nop when logger is nil
+func OrNop(logger Logger) Logger {
+	if logger == nil {
+		return Nop{}
+	}
+	return logger
+}